@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/deploy"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/journal"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// runAirdrop sends the transfers listed in a CSV file of address,amount
+// lines, or with -dry-run only simulates them. Each line is journaled under
+// the campaign and its line number, so rerunning the airdrop after a crash
+// or a failure pays only the lines that weren't.
+func runAirdrop(args []string) error {
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("airdrop", flag.ExitOnError)
+	file := fs.String("file", "", "CSV file of address,amount lines; amounts in tokens")
+	dryRun := fs.Bool("dry-run", false, "simulate the airdrop with eth_call and gas estimation, send nothing")
+	batch := fs.Bool("batch", false, "send in batches through a Disperse contract")
+	disperse := fs.String("disperse", "", "Disperse contract for -batch, an address or a deployment name (default the \"disperse\" deployment, deployed if missing)")
+	campaign := fs.String("campaign", "", "name the journal keeps the airdrop under (default the file name without extension)")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *disperse != "" && !common.IsHexAddress(*disperse) {
+		if err := registry.CheckName(*disperse); err != nil {
+			return fmt.Errorf("-disperse: %v", err)
+		}
+	}
+	if *campaign == "" {
+		*campaign = strings.TrimSuffix(filepath.Base(*file), filepath.Ext(*file))
+	}
+
+	session, err := connection.NewSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	token, err := tokenAddress(cfg, session)
+	if err != nil {
+		return err
+	}
+
+	unit, err := interact.TokenUnit(ctx, session.Client, token)
+	if err != nil {
+		return err
+	}
+	recipients, amounts, lines, err := readAirdropFile(*file, unit)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun && *batch {
+		disperseAddr, err := disperseAddress(ctx, cfg, session, *disperse, false)
+		if err != nil {
+			return err
+		}
+		sim, err := interact.SimulateBatch(ctx, session, token, disperseAddr.Hex(), recipients, amounts, interact.BatchOptions{})
+		if err != nil {
+			return err
+		}
+		sim.Print()
+		if sim.Reverts > 0 {
+			return fmt.Errorf("%d of %d batches would revert", sim.Reverts, len(sim.Batches))
+		}
+		return nil
+	}
+	if *dryRun {
+		sim, err := interact.SimulateTransfers(ctx, session, token, recipients, amounts)
+		if err != nil {
+			return err
+		}
+		sim.Print()
+		if sim.Reverts > 0 {
+			return fmt.Errorf("%d of %d transfers would revert", sim.Reverts, len(recipients))
+		}
+		return nil
+	}
+
+	j, err := interact.OpenJournal(ctx, session, cfg.Paths.JournalFile)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+	keys := make([]journal.Key, len(recipients))
+	for i, to := range recipients {
+		keys[i] = journal.Key{Campaign: *campaign, Line: lines[i], Recipient: to}
+	}
+
+	if *batch {
+		disperseAddr, err := disperseAddress(ctx, cfg, session, *disperse, true)
+		if err != nil {
+			return err
+		}
+		_, err = interact.BatchTransfer(ctx, session, token, disperseAddr.Hex(), recipients, amounts, interact.BatchOptions{Journal: j, Keys: keys})
+		return err
+	}
+
+	failed, skipped := 0, 0
+	for i, to := range recipients {
+		_, err := interact.TransferOnce(ctx, session, j, keys[i], token, amounts[i])
+		if errors.Is(err, interact.ErrAlreadyTransferred) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Transfer on line %d to %s failed: %v\n", lines[i], to.Hex(), err)
+			failed++
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d transfers the journal shows as paid\n", skipped)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transfers failed", failed, len(recipients))
+	}
+	return nil
+}
+
+// disperseAddress returns the Disperse contract -disperse names on the
+// session's chain. Without -disperse, that is the deployment named
+// registry.DefaultDisperse, which is deployed and recorded if missing and
+// deployMissing is set.
+func disperseAddress(ctx context.Context, cfg *config.Config, session *connection.Session, disperse string, deployMissing bool) (common.Address, error) {
+	if disperse != "" {
+		return registry.Resolve(cfg.Paths.DeploymentsFile, session.ChainID, disperse)
+	}
+	r, err := registry.Load(cfg.Paths.DeploymentsFile)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if d, ok := r.Lookup(session.ChainID, registry.DefaultDisperse); ok {
+		return d.Address, nil
+	}
+	if !deployMissing {
+		return common.Address{}, fmt.Errorf("no %q deployment on chain %d; a dry run needs a deployed Disperse, name one with -disperse or run without -dry-run once", registry.DefaultDisperse, session.ChainID)
+	}
+
+	deployment, err := deploy.DeployDisperse(ctx, session)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := deploy.Record(cfg.Paths.DeploymentsFile, session.ChainID, registry.DefaultDisperse, deployment, false); err != nil {
+		return common.Address{}, err
+	}
+	return deployment.Address, nil
+}
+
+// readAirdropFile reads address,amount lines and the line number of each;
+// blank lines and lines starting with # are skipped.
+func readAirdropFile(path string, unit units.Unit) ([]common.Address, []units.Amount, []int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open airdrop file: %v", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+
+	var recipients []common.Address
+	var amounts []units.Amount
+	var lines []int
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read airdrop file: %v", err)
+		}
+		line, _ := r.FieldPos(0)
+
+		address := strings.TrimSpace(record[0])
+		if !common.IsHexAddress(address) {
+			return nil, nil, nil, fmt.Errorf("%s:%d: %q is not an address", path, line, address)
+		}
+		amount, err := unit.Parse(record[1])
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if amount.Value.Sign() == 0 {
+			return nil, nil, nil, fmt.Errorf("%s:%d: amount must be positive", path, line)
+		}
+
+		recipients = append(recipients, common.HexToAddress(address))
+		amounts = append(amounts, amount)
+		lines = append(lines, line)
+	}
+	if len(recipients) == 0 {
+		return nil, nil, nil, fmt.Errorf("%s lists no transfers", path)
+	}
+	return recipients, amounts, lines, nil
+}
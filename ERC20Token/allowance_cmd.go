@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+const allowanceUsage = "usage: ERC20Token allowance approve|show|transfer-from [flags]"
+
+// runAllowance manages allowances of the token: the treasury
+// approves a distributor, which can then airdrop with transferFrom.
+func runAllowance(args []string) error {
+	if len(args) == 0 {
+		return errors.New(allowanceUsage)
+	}
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("allowance "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "approve":
+		spender := fs.String("spender", "", "account allowed to spend the signer's tokens")
+		amount := fs.String("amount", "", "tokens it may spend, e.g. 1.5 or \"1.5 TST\"; 0 revokes the allowance")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		spenderAddr, err := parseAddress("spender", *spender)
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewSession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		token, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		value, err := parseAmount(ctx, session, token, *amount)
+		if err != nil {
+			return err
+		}
+		_, err = interact.Approve(ctx, session, token, spenderAddr, value)
+		return err
+
+	case "show":
+		owner := fs.String("owner", "", "account whose tokens are spent (default generator.treasury)")
+		spender := fs.String("spender", "", "account allowed to spend them")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if *owner == "" {
+			*owner = cfg.Generator.Treasury
+		}
+		ownerAddr, err := parseAddress("owner", *owner)
+		if err != nil {
+			return err
+		}
+		spenderAddr, err := parseAddress("spender", *spender)
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		token, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		allowance, err := interact.Allowance(ctx, session.Client, token, ownerAddr, spenderAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s may spend %s of %s\n", spenderAddr.Hex(), allowance, ownerAddr.Hex())
+		return nil
+
+	case "transfer-from":
+		owner := fs.String("owner", "", "account the tokens come from (default generator.treasury)")
+		to := fs.String("to", "", "recipient")
+		amount := fs.String("amount", "", "tokens to send, e.g. 1.5 or \"1.5 TST\"")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if *owner == "" {
+			*owner = cfg.Generator.Treasury
+		}
+		ownerAddr, err := parseAddress("owner", *owner)
+		if err != nil {
+			return err
+		}
+		toAddr, err := parseAddress("to", *to)
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewSession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		token, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		value, err := parseAmount(ctx, session, token, *amount)
+		if err != nil {
+			return err
+		}
+		result, err := interact.TransferFrom(ctx, session, token, ownerAddr, toAddr, value)
+		printBalances(result)
+		return err
+
+	default:
+		return errors.New(allowanceUsage)
+	}
+}
+
+func parseAddress(name, value string) (common.Address, error) {
+	if value == "" {
+		return common.Address{}, fmt.Errorf("-%s is required", name)
+	}
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("-%s: %q is not an address", name, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+// parseAmount reads -amount in units of the token, e.g. "1.5" or "1.5 TST".
+func parseAmount(ctx context.Context, session *connection.Session, token, value string) (units.Amount, error) {
+	unit, err := interact.TokenUnit(ctx, session.Client, token)
+	if err != nil {
+		return units.Amount{}, err
+	}
+	amount, err := unit.Parse(value)
+	if err != nil {
+		return units.Amount{}, fmt.Errorf("-amount: %v", err)
+	}
+	return amount, nil
+}
+
+// tokenAddress returns the token the commands work with on the session's
+// chain: -token, an address or a deployment name, or the deployed one.
+func tokenAddress(cfg *config.Config, session *connection.Session) (string, error) {
+	address, err := interact.TokenAddressFromConfig(cfg, session.ChainID)
+	if err != nil {
+		return "", err
+	}
+	return address.Hex(), nil
+}
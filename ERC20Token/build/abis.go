@@ -0,0 +1,16 @@
+// Package build holds the contracts compiled from ../contracts: an .abi and
+// a .bin file per contract, including the interfaces and libraries they use.
+package build
+
+import "embed"
+
+// ABIs holds the .abi files of every compiled contract.
+//
+//go:embed *.abi
+var ABIs embed.FS
+
+// Bins holds the .bin files of every compiled contract: the creation
+// bytecode, hex-encoded, without constructor arguments.
+//
+//go:embed *.bin
+var Bins embed.FS
@@ -0,0 +1,638 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is read when neither -config nor CONFIG_FILE names a file.
+const DefaultFile = "config.yaml"
+
+// Config describes one deployment for all three binaries. Values are layered:
+// defaults, then the YAML file, then environment variables (including a .env
+// file in the working directory), then command-line flags.
+type Config struct {
+	RPC       RPC       `yaml:"rpc"`
+	Signer    Signer    `yaml:"signer"`
+	Fees      Fees      `yaml:"fees"`
+	Paths     Paths     `yaml:"paths"`
+	Generator Generator `yaml:"generator"`
+	Tracker   Tracker   `yaml:"tracker"`
+	Wallets   Wallets   `yaml:"wallets"`
+	// Token is the ERC20 contract to work with: an address, or the name of
+	// a deployment in the registry; the deployment named "token" if empty.
+	Token  string  `yaml:"token"`
+	Chains []Chain `yaml:"chains"`
+
+	// ConfirmChain must equal the chain ID before anything is sent to a
+	// production chain. It is only taken from a flag or the environment the
+	// command was started with, so that neither a config file nor .env can
+	// confirm every run for good.
+	ConfirmChain uint64 `yaml:"-"`
+}
+
+type RPC struct {
+	// Backend is rpc to use the endpoints in URLs, or simulated for an
+	// in-process chain that lives as long as the command.
+	Backend        string        `yaml:"backend"`
+	URLs           []string      `yaml:"urls"`
+	Strategy       string        `yaml:"strategy"`
+	MaxBlockLag    uint64        `yaml:"max_block_lag"`
+	HealthInterval time.Duration `yaml:"health_interval"`
+
+	// RateLimit caps requests per second over all endpoints; 0 disables it.
+	RateLimit   float64                `yaml:"rate_limit"`
+	RateBurst   int                    `yaml:"rate_burst"`
+	Retry       RetryPolicy            `yaml:"retry"`
+	MethodRetry map[string]RetryPolicy `yaml:"method_retry"`
+}
+
+// RetryPolicy controls how failed RPC calls are retried. In method_retry,
+// zero fields inherit from rpc.retry.
+type RetryPolicy struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// Signer selects where the deployer key comes from. PrivateKey is only ever
+// taken from the environment so it can't end up in a committed config file.
+type Signer struct {
+	Type                 string `yaml:"type"`
+	PrivateKey           string `yaml:"-"`
+	KeystoreFile         string `yaml:"keystore_file"`
+	KeystorePasswordFile string `yaml:"keystore_password_file"`
+	MnemonicFile         string `yaml:"mnemonic_file"`
+	MnemonicPasswordFile string `yaml:"mnemonic_password_file"`
+	HDPath               string `yaml:"hd_path"`
+	URL                  string `yaml:"url"`
+	Account              string `yaml:"account"`
+}
+
+// Fees selects how transactions are priced. Amounts are in gwei.
+type Fees struct {
+	Strategy        string `yaml:"strategy"`
+	MaxFeeGwei      string `yaml:"max_fee_gwei"`
+	PriorityFeeGwei string `yaml:"priority_fee_gwei"`
+	Legacy          bool   `yaml:"legacy"`
+}
+
+type Paths struct {
+	HashFile string `yaml:"hash_file"`
+	// DeploymentsFile is the registry of deployed contracts, by chain ID
+	// and name.
+	DeploymentsFile string `yaml:"deployments_file"`
+	// JournalFile records every transfer of the generator and airdrops so
+	// that repeated runs don't pay twice.
+	JournalFile string `yaml:"journal_file"`
+}
+
+type Generator struct {
+	Interval         time.Duration `yaml:"interval"`
+	Recipients       int           `yaml:"recipients"`
+	TransfersPerTick int           `yaml:"transfers_per_tick"`
+	// Treasury, if set, is the account the tokens come from: the signer
+	// spends the allowance it was given with transferFrom instead of
+	// sending its own tokens.
+	Treasury string `yaml:"treasury"`
+	// Campaign names the generator's transfers in the journal. The
+	// recipient and amount of every transfer follow from the campaign and
+	// its number, so a restarted generator skips what was already paid.
+	Campaign string `yaml:"campaign"`
+}
+
+// Tracker selects where transfers are read from: the token's Transfer logs,
+// pushed over a ws:// subscription or polled with eth_getLogs, or the hash
+// file written by the generator.
+type Tracker struct {
+	Interval            time.Duration `yaml:"interval"`
+	Source              string        `yaml:"source"`
+	ResubscribeInterval time.Duration `yaml:"resubscribe_interval"`
+}
+
+// Wallets configures the encrypted store of generated and imported keys,
+// among them the recipients the generator airdrops to.
+type Wallets struct {
+	Dir          string `yaml:"dir"`
+	PasswordFile string `yaml:"password_file"`
+	LightKDF     bool   `yaml:"light_kdf"`
+}
+
+// Chain adds a chain profile or overrides a built-in one. ExplorerTxURL
+// contains "%s" where the transaction hash goes.
+type Chain struct {
+	ChainID       uint64 `yaml:"chain_id"`
+	Name          string `yaml:"name"`
+	ExplorerTxURL string `yaml:"explorer_tx_url"`
+	Confirmations uint64 `yaml:"confirmations"`
+	Production    bool   `yaml:"production"`
+}
+
+// Signer types.
+const (
+	SignerKey      = "key"
+	SignerKeystore = "keystore"
+	SignerMnemonic = "mnemonic"
+	SignerRemote   = "remote"
+)
+
+// RPC backends.
+const (
+	BackendRPC       = "rpc"
+	BackendSimulated = "simulated"
+)
+
+// Tracker sources.
+const (
+	TrackerLogs     = "logs"
+	TrackerHashFile = "hash-file"
+)
+
+// Fee strategies. slow, normal and fast sample recent priority fees at a low,
+// middle and high percentile; fixed uses the configured max and priority fee.
+const (
+	FeeSlow   = "slow"
+	FeeNormal = "normal"
+	FeeFast   = "fast"
+	FeeFixed  = "fixed"
+)
+
+// Default returns the configuration used when nothing else is set.
+func Default() *Config {
+	return &Config{
+		RPC: RPC{
+			Backend:        BackendRPC,
+			URLs:           []string{"http://localhost:8545"},
+			Strategy:       "failover",
+			MaxBlockLag:    5,
+			HealthInterval: 10 * time.Second,
+			Retry: RetryPolicy{
+				MaxAttempts: 4,
+				BaseDelay:   200 * time.Millisecond,
+				MaxDelay:    5 * time.Second,
+				Timeout:     15 * time.Second,
+			},
+		},
+		Signer: Signer{Type: SignerKey},
+		Fees:   Fees{Strategy: FeeNormal},
+		Paths: Paths{
+			HashFile:        "hash.txt",
+			DeploymentsFile: "deployments.json",
+			JournalFile:     "journal.jsonl",
+		},
+		Generator: Generator{
+			Interval:         5 * time.Second,
+			Recipients:       10,
+			TransfersPerTick: 1,
+			Campaign:         "generator",
+		},
+		Tracker: Tracker{
+			Interval:            5 * time.Second,
+			Source:              TrackerLogs,
+			ResubscribeInterval: 30 * time.Second,
+		},
+		Wallets: Wallets{Dir: "wallets"},
+	}
+}
+
+// setting binds one value to its environment variable and flag.
+type setting struct {
+	env, flag, usage string
+	set              func(c *Config, v string) error
+}
+
+var settings = []setting{
+	{"RPC_BACKEND", "backend", "rpc, or simulated for an in-process chain", func(c *Config, v string) error {
+		c.RPC.Backend = v
+		return nil
+	}},
+	{"GANACHE_URL", "rpc", "comma-separated RPC endpoints", func(c *Config, v string) error {
+		c.RPC.URLs = splitList(v)
+		return nil
+	}},
+	{"RPC_STRATEGY", "rpc-strategy", "RPC pool strategy: failover or round-robin", func(c *Config, v string) error {
+		c.RPC.Strategy = v
+		return nil
+	}},
+	{"RPC_MAX_BLOCK_LAG", "rpc-max-block-lag", "blocks an endpoint may trail the best one", func(c *Config, v string) (err error) {
+		c.RPC.MaxBlockLag, err = strconv.ParseUint(v, 10, 64)
+		return err
+	}},
+	{"RPC_HEALTH_INTERVAL", "rpc-health-interval", "time between RPC health checks", func(c *Config, v string) (err error) {
+		c.RPC.HealthInterval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"RPC_TIMEOUT", "rpc-timeout", "deadline for a single RPC call attempt", func(c *Config, v string) (err error) {
+		c.RPC.Retry.Timeout, err = time.ParseDuration(v)
+		return err
+	}},
+	{"RPC_MAX_ATTEMPTS", "rpc-max-attempts", "attempts per RPC call before giving up", func(c *Config, v string) (err error) {
+		c.RPC.Retry.MaxAttempts, err = strconv.Atoi(v)
+		return err
+	}},
+	{"RPC_RATE_LIMIT", "rpc-rate-limit", "RPC requests per second over all endpoints, 0 for no limit", func(c *Config, v string) (err error) {
+		c.RPC.RateLimit, err = strconv.ParseFloat(v, 64)
+		return err
+	}},
+	{"RPC_RATE_BURST", "rpc-rate-burst", "requests allowed in a burst above the rate limit", func(c *Config, v string) (err error) {
+		c.RPC.RateBurst, err = strconv.Atoi(v)
+		return err
+	}},
+	{"SIGNER_TYPE", "signer", "signer backend: key, keystore, mnemonic or remote", func(c *Config, v string) error {
+		c.Signer.Type = v
+		return nil
+	}},
+	{"DEPLOYER_PRIVATE_KEY", "", "", func(c *Config, v string) error {
+		c.Signer.PrivateKey = v
+		return nil
+	}},
+	{"KEYSTORE_FILE", "keystore", "keystore JSON file of the deployer", func(c *Config, v string) error {
+		c.Signer.KeystoreFile = v
+		return nil
+	}},
+	{"KEYSTORE_PASSWORD_FILE", "keystore-password-file", "file holding the keystore passphrase", func(c *Config, v string) error {
+		c.Signer.KeystorePasswordFile = v
+		return nil
+	}},
+	{"MNEMONIC_FILE", "mnemonic-file", "file holding the BIP-39 mnemonic", func(c *Config, v string) error {
+		c.Signer.MnemonicFile = v
+		return nil
+	}},
+	{"MNEMONIC_PASSWORD_FILE", "mnemonic-password-file", "file holding the BIP-39 passphrase", func(c *Config, v string) error {
+		c.Signer.MnemonicPasswordFile = v
+		return nil
+	}},
+	{"HD_PATH", "hd-path", "derivation path for the mnemonic signer", func(c *Config, v string) error {
+		c.Signer.HDPath = v
+		return nil
+	}},
+	{"SIGNER_URL", "signer-url", "JSON-RPC endpoint of the remote signer", func(c *Config, v string) error {
+		c.Signer.URL = v
+		return nil
+	}},
+	{"SIGNER_ACCOUNT", "signer-account", "account to use on the remote signer", func(c *Config, v string) error {
+		c.Signer.Account = v
+		return nil
+	}},
+	{"FEE_STRATEGY", "fee-strategy", "fee strategy: slow, normal, fast or fixed", func(c *Config, v string) error {
+		c.Fees.Strategy = v
+		return nil
+	}},
+	{"MAX_FEE_GWEI", "max-fee-gwei", "upper bound for the fee per gas, in gwei", func(c *Config, v string) error {
+		c.Fees.MaxFeeGwei = v
+		return nil
+	}},
+	{"PRIORITY_FEE_GWEI", "priority-fee-gwei", "priority fee for the fixed strategy, in gwei", func(c *Config, v string) error {
+		c.Fees.PriorityFeeGwei = v
+		return nil
+	}},
+	{"LEGACY_TX", "legacy-tx", "send legacy transactions even if the chain supports EIP-1559 (true/false)", func(c *Config, v string) (err error) {
+		c.Fees.Legacy, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"HASH_FILE", "hash-file", "file the generator writes and the tracker reads transaction hashes from", func(c *Config, v string) error {
+		c.Paths.HashFile = v
+		return nil
+	}},
+	{"DEPLOYMENTS_FILE", "deployments-file", "registry of deployed contracts by chain and name", func(c *Config, v string) error {
+		c.Paths.DeploymentsFile = v
+		return nil
+	}},
+	{"JOURNAL_FILE", "journal-file", "file recording every transfer so that repeated runs don't pay twice", func(c *Config, v string) error {
+		c.Paths.JournalFile = v
+		return nil
+	}},
+	{"TOKEN_ADDRESS", "token", "ERC20 token to work with: an address or a deployment name (default \"token\")", func(c *Config, v string) error {
+		c.Token = v
+		return nil
+	}},
+	{"GENERATOR_INTERVAL", "generator-interval", "time between generator ticks", func(c *Config, v string) (err error) {
+		c.Generator.Interval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"RECIPIENTS", "recipients", "number of recipient addresses the generator pays", func(c *Config, v string) (err error) {
+		c.Generator.Recipients, err = strconv.Atoi(v)
+		return err
+	}},
+	{"TRANSFERS_PER_TICK", "transfers-per-tick", "parallel transfers sent on every generator tick", func(c *Config, v string) (err error) {
+		c.Generator.TransfersPerTick, err = strconv.Atoi(v)
+		return err
+	}},
+	{"GENERATOR_TREASURY", "treasury", "account the generator airdrops from with transferFrom", func(c *Config, v string) error {
+		c.Generator.Treasury = v
+		return nil
+	}},
+	{"GENERATOR_CAMPAIGN", "generator-campaign", "name of the generator's transfers in the journal", func(c *Config, v string) error {
+		c.Generator.Campaign = v
+		return nil
+	}},
+	{"TRACKER_INTERVAL", "tracker-interval", "time between tracker ticks", func(c *Config, v string) (err error) {
+		c.Tracker.Interval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"TRACKER_SOURCE", "tracker-source", "where the tracker reads transfers from: logs or hash-file", func(c *Config, v string) error {
+		c.Tracker.Source = v
+		return nil
+	}},
+	{"TRACKER_RESUBSCRIBE_INTERVAL", "tracker-resubscribe-interval", "time between attempts to get back from polling to a subscription", func(c *Config, v string) (err error) {
+		c.Tracker.ResubscribeInterval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"WALLET_DIR", "wallet-dir", "keystore directory of the wallet store", func(c *Config, v string) error {
+		c.Wallets.Dir = v
+		return nil
+	}},
+	{"WALLET_PASSWORD_FILE", "wallet-password-file", "file holding the wallet store passphrase", func(c *Config, v string) error {
+		c.Wallets.PasswordFile = v
+		return nil
+	}},
+	{"WALLET_LIGHT_KDF", "wallet-light-kdf", "encrypt wallets with light scrypt parameters (true/false)", func(c *Config, v string) (err error) {
+		c.Wallets.LightKDF, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"CONFIRM_CHAIN", "confirm-chain", "chain ID to confirm sending transactions to a production chain", func(c *Config, v string) (err error) {
+		c.ConfirmChain, err = strconv.ParseUint(v, 10, 64)
+		return err
+	}},
+}
+
+// Load registers the shared flags on fs, parses args and returns the
+// validated configuration. Commands may register their own flags on fs
+// before calling Load.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	configFile := fs.String("config", "", "YAML config file (default $CONFIG_FILE or "+DefaultFile+")")
+	flagValues := make(map[string]*string)
+	for _, s := range settings {
+		if s.flag != "" {
+			flagValues[s.flag] = fs.String(s.flag, "", s.usage+" ($"+s.env+")")
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// a confirmation comes with the run, never from .env
+	confirm, confirmed := os.LookupEnv("CONFIRM_CHAIN")
+
+	// values already exported in the environment win over .env
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %v", err)
+	}
+
+	cfg := Default()
+
+	path, explicit := *configFile, *configFile != ""
+	if !explicit {
+		path, explicit = os.LookupEnv("CONFIG_FILE")
+	}
+	if !explicit {
+		path = DefaultFile
+	}
+	if err := cfg.readFile(path, explicit); err != nil {
+		return nil, err
+	}
+
+	for _, s := range settings {
+		v, ok := os.LookupEnv(s.env)
+		if s.env == "CONFIRM_CHAIN" {
+			v, ok = confirm, confirmed
+		}
+		if ok && v != "" {
+			if err := s.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", s.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && flagErr == nil {
+				if err := s.set(cfg, *flagValues[s.flag]); err != nil {
+					flagErr = fmt.Errorf("invalid -%s: %v", s.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readFile merges the YAML file at path into c. A missing file is only an
+// error when it was asked for explicitly.
+func (c *Config) readFile(path string, required bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %v", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate checks that the configuration is complete and consistent.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.RPC.Backend != BackendRPC && c.RPC.Backend != BackendSimulated {
+		errs = append(errs, fmt.Errorf("rpc.backend: unknown backend %q", c.RPC.Backend))
+	}
+	if len(c.RPC.URLs) == 0 && c.RPC.Backend == BackendRPC {
+		errs = append(errs, errors.New("rpc.urls: at least one endpoint is required"))
+	}
+	for _, raw := range c.RPC.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss") {
+			errs = append(errs, fmt.Errorf("rpc.urls: %q is not an http(s) or ws(s) URL", raw))
+		}
+	}
+	if c.RPC.Strategy != "failover" && c.RPC.Strategy != "round-robin" {
+		errs = append(errs, fmt.Errorf("rpc.strategy: unknown strategy %q", c.RPC.Strategy))
+	}
+	if c.RPC.HealthInterval <= 0 {
+		errs = append(errs, errors.New("rpc.health_interval: must be positive"))
+	}
+	if c.RPC.RateLimit < 0 {
+		errs = append(errs, errors.New("rpc.rate_limit: must not be negative"))
+	}
+	if c.RPC.RateBurst < 0 {
+		errs = append(errs, errors.New("rpc.rate_burst: must not be negative"))
+	}
+	if c.RPC.Retry.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("rpc.retry.max_attempts: must be positive"))
+	}
+	errs = append(errs, c.RPC.Retry.validate("rpc.retry")...)
+	for method, policy := range c.RPC.MethodRetry {
+		if policy.MaxAttempts < 0 {
+			errs = append(errs, fmt.Errorf("rpc.method_retry.%s.max_attempts: must not be negative", method))
+		}
+		errs = append(errs, policy.validate("rpc.method_retry."+method)...)
+	}
+
+	switch c.Signer.Type {
+	case SignerKey:
+	case SignerKeystore:
+		if c.Signer.KeystoreFile == "" {
+			errs = append(errs, errors.New("signer.keystore_file: required for the keystore signer"))
+		}
+	case SignerMnemonic:
+		if c.Signer.MnemonicFile == "" {
+			errs = append(errs, errors.New("signer.mnemonic_file: required for the mnemonic signer"))
+		}
+	case SignerRemote:
+		if c.Signer.URL == "" {
+			errs = append(errs, errors.New("signer.url: required for the remote signer"))
+		}
+		if c.Signer.Account != "" && !common.IsHexAddress(c.Signer.Account) {
+			errs = append(errs, fmt.Errorf("signer.account: %q is not an address", c.Signer.Account))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("signer.type: unknown signer %q", c.Signer.Type))
+	}
+
+	switch c.Fees.Strategy {
+	case FeeSlow, FeeNormal, FeeFast:
+	case FeeFixed:
+		if c.Fees.MaxFeeGwei == "" {
+			errs = append(errs, errors.New("fees.max_fee_gwei: required for the fixed strategy"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("fees.strategy: unknown strategy %q", c.Fees.Strategy))
+	}
+	if c.Fees.MaxFeeGwei != "" {
+		if _, err := ParseGwei(c.Fees.MaxFeeGwei); err != nil {
+			errs = append(errs, fmt.Errorf("fees.max_fee_gwei: %v", err))
+		}
+	}
+	if c.Fees.PriorityFeeGwei != "" {
+		if _, err := ParseGwei(c.Fees.PriorityFeeGwei); err != nil {
+			errs = append(errs, fmt.Errorf("fees.priority_fee_gwei: %v", err))
+		}
+	}
+
+	if c.Paths.HashFile == "" {
+		errs = append(errs, errors.New("paths.hash_file: required"))
+	}
+	if c.Paths.DeploymentsFile == "" {
+		errs = append(errs, errors.New("paths.deployments_file: required"))
+	}
+	if c.Paths.JournalFile == "" {
+		errs = append(errs, errors.New("paths.journal_file: required"))
+	}
+	// anything else names a deployment
+	if strings.HasPrefix(c.Token, "0x") && !common.IsHexAddress(c.Token) {
+		errs = append(errs, fmt.Errorf("token: %q is not an address", c.Token))
+	}
+	if c.Generator.Interval <= 0 {
+		errs = append(errs, errors.New("generator.interval: must be positive"))
+	}
+	if c.Generator.Recipients <= 0 {
+		errs = append(errs, errors.New("generator.recipients: must be positive"))
+	}
+	if c.Generator.TransfersPerTick <= 0 {
+		errs = append(errs, errors.New("generator.transfers_per_tick: must be positive"))
+	}
+	if c.Generator.Treasury != "" && !common.IsHexAddress(c.Generator.Treasury) {
+		errs = append(errs, fmt.Errorf("generator.treasury: %q is not an address", c.Generator.Treasury))
+	}
+	if c.Generator.Campaign == "" {
+		errs = append(errs, errors.New("generator.campaign: required"))
+	}
+	if c.Tracker.Interval <= 0 {
+		errs = append(errs, errors.New("tracker.interval: must be positive"))
+	}
+	if c.Tracker.Source != TrackerLogs && c.Tracker.Source != TrackerHashFile {
+		errs = append(errs, fmt.Errorf("tracker.source: unknown source %q", c.Tracker.Source))
+	}
+	if c.Tracker.ResubscribeInterval <= 0 {
+		errs = append(errs, errors.New("tracker.resubscribe_interval: must be positive"))
+	}
+	if c.Wallets.Dir == "" {
+		errs = append(errs, errors.New("wallets.dir: required"))
+	}
+	seen := make(map[uint64]bool)
+	for i, chain := range c.Chains {
+		if chain.ChainID == 0 {
+			errs = append(errs, fmt.Errorf("chains[%d].chain_id: required", i))
+		} else if seen[chain.ChainID] {
+			errs = append(errs, fmt.Errorf("chains[%d].chain_id: %d is listed twice", i, chain.ChainID))
+		}
+		seen[chain.ChainID] = true
+		if chain.Name == "" {
+			errs = append(errs, fmt.Errorf("chains[%d].name: required", i))
+		}
+		if chain.ExplorerTxURL != "" && !strings.Contains(chain.ExplorerTxURL, "%s") {
+			errs = append(errs, fmt.Errorf("chains[%d].explorer_tx_url: must contain %%s for the transaction hash", i))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (p RetryPolicy) validate(prefix string) []error {
+	var errs []error
+	if p.BaseDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.base_delay: must not be negative", prefix))
+	}
+	if p.MaxDelay < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_delay: must not be negative", prefix))
+	}
+	if p.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%s.timeout: must not be negative", prefix))
+	}
+	return errs
+}
+
+// ParseGwei converts a decimal gwei amount such as "1.5" to wei.
+func ParseGwei(v string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(v))
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a non-negative number", v)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(params.GWei))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q has more than 9 decimals", v)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
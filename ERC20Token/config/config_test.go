@@ -0,0 +1,221 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// setup runs the test in a directory holding files, with none of the
+// settings in the environment but those in env. Whatever .env adds to the
+// environment is taken out again afterwards.
+func setup(t *testing.T, files map[string]string, env map[string]string) {
+	t.Helper()
+	for _, name := range append([]string{"CONFIG_FILE"}, envNames()...) {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func envNames() []string {
+	var names []string
+	for _, s := range settings {
+		names = append(names, s.env)
+	}
+	return names
+}
+
+func load(args ...string) (*Config, error) {
+	return Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+// Each setting below is given in one more layer than the one before it, so
+// each shows that layer winning over those under it.
+func TestLoadPrecedence(t *testing.T) {
+	setup(t, map[string]string{
+		"config.yaml": `
+paths:
+  hash_file: file.txt
+generator:
+  campaign: file
+  transfers_per_tick: 2
+  recipients: 2
+`,
+		".env": "GENERATOR_CAMPAIGN=dotenv\nTRANSFERS_PER_TICK=3\nRECIPIENTS=3\n",
+	}, map[string]string{
+		"TRANSFERS_PER_TICK": "4",
+		"RECIPIENTS":         "4",
+	})
+	cfg, err := load("-recipients", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		layer     string
+		got, want string
+	}{
+		{"default", cfg.Generator.Interval.String(), "5s"},
+		{"file over default", cfg.Paths.HashFile, "file.txt"},
+		{".env over file", cfg.Generator.Campaign, "dotenv"},
+		{"environment over .env", strconv.Itoa(cfg.Generator.TransfersPerTick), "4"},
+		{"flag over environment", strconv.Itoa(cfg.Generator.Recipients), "5"},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s: got %s, want %s", test.layer, test.got, test.want)
+		}
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "generator:\n  campaign: default\n",
+		"other.yaml":  "generator:\n  campaign: other\n",
+	}
+	for _, test := range []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		campaign string
+		errText  string
+	}{
+		{"default file", nil, nil, "default", ""},
+		{"CONFIG_FILE", map[string]string{"CONFIG_FILE": "other.yaml"}, nil, "other", ""},
+		{"flag over CONFIG_FILE", map[string]string{"CONFIG_FILE": "missing.yaml"}, []string{"-config", "other.yaml"}, "other", ""},
+		{"missing file asked for", nil, []string{"-config", "missing.yaml"}, "", "failed to open config file"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			setup(t, files, test.env)
+			cfg, err := load(test.args...)
+			if test.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errText) {
+					t.Fatalf("error is %v, want one containing %q", err, test.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Generator.Campaign != test.campaign {
+				t.Fatalf("campaign is %q, want %q", cfg.Generator.Campaign, test.campaign)
+			}
+		})
+	}
+}
+
+// A production chain is confirmed for one run, so CONFIRM_CHAIN counts from
+// the environment or a flag but never from .env.
+func TestLoadConfirmChain(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		dotenv  string
+		env     map[string]string
+		args    []string
+		confirm uint64
+	}{
+		{"none", "", nil, nil, 0},
+		{".env", "CONFIRM_CHAIN=1\n", nil, nil, 0},
+		{"environment", "", map[string]string{"CONFIRM_CHAIN": "1"}, nil, 1},
+		{"flag", "CONFIRM_CHAIN=5\n", nil, []string{"-confirm-chain", "1"}, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			setup(t, map[string]string{".env": test.dotenv}, test.env)
+			cfg, err := load(test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.ConfirmChain != test.confirm {
+				t.Fatalf("confirmed chain is %d, want %d", cfg.ConfirmChain, test.confirm)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		env     map[string]string
+		args    []string
+		errText string
+	}{
+		{"bad environment value", nil, map[string]string{"RECIPIENTS": "ten"}, nil, "invalid RECIPIENTS"},
+		{"bad flag value", nil, nil, []string{"-rpc-health-interval", "often"}, "invalid -rpc-health-interval"},
+		{"unknown field in the file", map[string]string{"config.yaml": "generator:\n  recipient: 3\n"}, nil, nil, "failed to parse config file"},
+		{"invalid result", nil, map[string]string{"RPC_STRATEGY": "random"}, nil, `rpc.strategy: unknown strategy "random"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			setup(t, test.files, test.env)
+			_, err := load(test.args...)
+			if err == nil || !strings.Contains(err.Error(), test.errText) {
+				t.Fatalf("error is %v, want one containing %q", err, test.errText)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		change  func(c *Config)
+		errText string // empty if valid
+	}{
+		{"default", func(c *Config) {}, ""},
+		{"simulated without urls", func(c *Config) { c.RPC.Backend, c.RPC.URLs = BackendSimulated, nil }, ""},
+		{"unknown backend", func(c *Config) { c.RPC.Backend = "ipc" }, `rpc.backend: unknown backend "ipc"`},
+		{"no urls", func(c *Config) { c.RPC.URLs = nil }, "rpc.urls: at least one endpoint is required"},
+		{"bad url", func(c *Config) { c.RPC.URLs = []string{"localhost:8545"} }, `"localhost:8545" is not an http(s) or ws(s) URL`},
+		{"no attempts", func(c *Config) { c.RPC.Retry.MaxAttempts = 0 }, "rpc.retry.max_attempts: must be positive"},
+		{"negative method delay", func(c *Config) {
+			c.RPC.MethodRetry = map[string]RetryPolicy{"eth_call": {BaseDelay: -1}}
+		}, "rpc.method_retry.eth_call.base_delay: must not be negative"},
+		{"keystore without file", func(c *Config) { c.Signer.Type = SignerKeystore }, "signer.keystore_file: required"},
+		{"remote account", func(c *Config) {
+			c.Signer.Type, c.Signer.URL, c.Signer.Account = SignerRemote, "http://localhost:8550", "me"
+		}, `signer.account: "me" is not an address`},
+		{"unknown signer", func(c *Config) { c.Signer.Type = "ledger" }, `signer.type: unknown signer "ledger"`},
+		{"fixed fees without cap", func(c *Config) { c.Fees.Strategy = FeeFixed }, "fees.max_fee_gwei: required for the fixed strategy"},
+		{"fee with too many decimals", func(c *Config) { c.Fees.MaxFeeGwei = "0.0000000001" }, "has more than 9 decimals"},
+		{"token address", func(c *Config) { c.Token = "0x1234" }, `token: "0x1234" is not an address`},
+		{"token name", func(c *Config) { c.Token = "usdc" }, ""},
+		{"no recipients", func(c *Config) { c.Generator.Recipients = 0 }, "generator.recipients: must be positive"},
+		{"unknown tracker source", func(c *Config) { c.Tracker.Source = "blocks" }, `tracker.source: unknown source "blocks"`},
+		{"chain twice", func(c *Config) {
+			c.Chains = []Chain{{ChainID: 5, Name: "a"}, {ChainID: 5, Name: "b"}}
+		}, "chains[1].chain_id: 5 is listed twice"},
+		{"explorer without placeholder", func(c *Config) {
+			c.Chains = []Chain{{ChainID: 5, Name: "a", ExplorerTxURL: "https://example.org/tx/"}}
+		}, "chains[0].explorer_tx_url: must contain %s"},
+		{"later problem after an earlier one", func(c *Config) { c.RPC.Strategy, c.Wallets.Dir = "", "" }, "wallets.dir: required"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Default()
+			test.change(cfg)
+			err := cfg.Validate()
+			if test.errText == "" && err != nil || test.errText != "" && (err == nil || !strings.Contains(err.Error(), test.errText)) {
+				t.Fatalf("error is %v, want %q", err, test.errText)
+			}
+		})
+	}
+}
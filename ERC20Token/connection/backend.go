@@ -0,0 +1,34 @@
+package connection
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend is everything the commands need from a node: what the generated
+// bindings need to call, transact, filter and deploy, plus the chain and
+// account reads on top. Pool implements it, RetryBackend wraps another
+// Backend, and SimulatedBackend runs a chain in-process.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+var (
+	_ Backend = (*Pool)(nil)
+	_ Backend = (*RetryBackend)(nil)
+	_ Backend = (*SimulatedBackend)(nil)
+)
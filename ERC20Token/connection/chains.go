@@ -0,0 +1,127 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
+
+// ChainProfile describes what we know about a chain: how to name it, where to
+// look transactions up, how many blocks make a transaction final enough for
+// us, and whether it carries real value.
+type ChainProfile struct {
+	ChainID       uint64
+	Name          string
+	ExplorerTxURL string // "%s" is replaced by the transaction hash
+	Confirmations uint64
+	Production    bool
+}
+
+var (
+	chainsMu sync.RWMutex
+	chains   = map[uint64]ChainProfile{
+		1:        {ChainID: 1, Name: "Ethereum mainnet", ExplorerTxURL: "https://etherscan.io/tx/%s", Confirmations: 12, Production: true},
+		10:       {ChainID: 10, Name: "OP mainnet", ExplorerTxURL: "https://optimistic.etherscan.io/tx/%s", Confirmations: 10, Production: true},
+		56:       {ChainID: 56, Name: "BNB Smart Chain", ExplorerTxURL: "https://bscscan.com/tx/%s", Confirmations: 15, Production: true},
+		137:      {ChainID: 137, Name: "Polygon PoS", ExplorerTxURL: "https://polygonscan.com/tx/%s", Confirmations: 64, Production: true},
+		8453:     {ChainID: 8453, Name: "Base", ExplorerTxURL: "https://basescan.org/tx/%s", Confirmations: 10, Production: true},
+		42161:    {ChainID: 42161, Name: "Arbitrum One", ExplorerTxURL: "https://arbiscan.io/tx/%s", Confirmations: 10, Production: true},
+		17000:    {ChainID: 17000, Name: "Holesky testnet", ExplorerTxURL: "https://holesky.etherscan.io/tx/%s", Confirmations: 3},
+		11155111: {ChainID: 11155111, Name: "Sepolia testnet", ExplorerTxURL: "https://sepolia.etherscan.io/tx/%s", Confirmations: 3},
+		1337:     {ChainID: 1337, Name: "local Ganache", Confirmations: 1},
+		5777:     {ChainID: 5777, Name: "local Ganache", Confirmations: 1},
+		31337:    {ChainID: 31337, Name: "local Hardhat/Anvil", Confirmations: 1},
+	}
+)
+
+// RegisterChain adds or replaces a chain profile.
+func RegisterChain(profile ChainProfile) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+
+	chains[profile.ChainID] = profile
+}
+
+// RegisterChainsFromConfig adds the profiles listed in the chains section.
+func RegisterChainsFromConfig(profiles []config.Chain) {
+	for _, c := range profiles {
+		RegisterChain(ChainProfile{
+			ChainID:       c.ChainID,
+			Name:          c.Name,
+			ExplorerTxURL: c.ExplorerTxURL,
+			Confirmations: c.Confirmations,
+			Production:    c.Production,
+		})
+	}
+}
+
+// LookupChain returns the profile for chainID. Chains we know nothing about
+// are treated as production, so that a mistyped URL fails safe.
+func LookupChain(chainID *big.Int) ChainProfile {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+
+	if chainID.IsUint64() {
+		if profile, ok := chains[chainID.Uint64()]; ok {
+			return profile
+		}
+	}
+	return ChainProfile{
+		ChainID:       chainID.Uint64(),
+		Name:          fmt.Sprintf("unknown chain %s", chainID),
+		Confirmations: 12,
+		Production:    true,
+	}
+}
+
+// TxURL links to hash on the chain's explorer, or returns "" without one.
+func (p ChainProfile) TxURL(hash common.Hash) string {
+	if p.ExplorerTxURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(p.ExplorerTxURL, "%s", hash.Hex())
+}
+
+// ProductionChainError is returned when a transaction would be sent to a
+// production chain without the operator confirming that chain ID.
+type ProductionChainError struct {
+	Profile ChainProfile
+}
+
+func (e *ProductionChainError) Error() string {
+	return fmt.Sprintf("refusing to send transactions to %s (chain ID %d): it is a production chain, rerun with -confirm-chain %d to proceed",
+		e.Profile.Name, e.Profile.ChainID, e.Profile.ChainID)
+}
+
+// WaitConfirmations blocks until blockNumber is buried under the profile's
+// confirmation depth.
+func (s *Session) WaitConfirmations(ctx context.Context, blockNumber *big.Int) error {
+	if s.Profile.Confirmations <= 1 || blockNumber == nil {
+		return nil
+	}
+	target := blockNumber.Uint64() + s.Profile.Confirmations - 1
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		head, err := s.Client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get block number: %v", err)
+		}
+		if head >= target {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
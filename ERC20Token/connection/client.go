@@ -3,114 +3,269 @@ package connection
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
-	"os"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/joho/godotenv"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
 )
 
-func GoDotEnvVariable(key string) string {
-	// load .env file
-	err := godotenv.Load(".env")
+// ErrReadOnlySession is returned when a transaction is requested from a session
+// that was opened without a signer.
+var ErrReadOnlySession = errors.New("session has no signer")
 
-	if err != nil {
-		log.Fatalf("Error loading .env file")
-	}
+// Session holds one connection to the node together with the account that
+// signs transactions for it. Every method reports failures to the caller
+// instead of exiting, so long-running loops can retry or skip. Client retries
+// transient failures of the calls it makes through Pool.
+type Session struct {
+	Client  Backend
+	Pool    *Pool
+	ChainID *big.Int
+	From    common.Address
+	Signer  Signer
+	Nonces  *NonceManager
+	Fees    *FeeOracle
+	Profile ChainProfile
+
+	confirmedChain uint64
+}
 
-	return os.Getenv(key)
+// Options tunes the pool, retries and fee oracle of a session. ConfirmChain
+// must equal the chain ID for the session to send transactions to a
+// production chain.
+type Options struct {
+	Pool         PoolOptions
+	Retry        RetryOptions
+	Fees         FeeOptions
+	ConfirmChain uint64
 }
 
-func Connection() (*ethclient.Client, *big.Int, common.Address, *ecdsa.PrivateKey) {
-	//poor quality code that the Connection restricted the transaction to the contract owner's address only
-	client, err := ethclient.Dial(GoDotEnvVariable("GANACHE_URL"))
+// NewSession connects to the configured RPC endpoints and signs with the
+// configured signer.
+func NewSession(ctx context.Context, cfg *config.Config) (*Session, error) {
+	var signer Signer
+	var err error
+	if cfg.RPC.Backend == config.BackendSimulated && cfg.Signer.Type == config.SignerKey && cfg.Signer.PrivateKey == "" {
+		// nothing to protect on a throwaway chain: fund a fresh key
+		var key *ecdsa.PrivateKey
+		if key, err = crypto.GenerateKey(); err == nil {
+			signer = NewKeySigner(key)
+		}
+	} else {
+		signer, err = NewSigner(ctx, cfg.Signer)
+	}
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	chainID, err := client.ChainID(context.Background())
+	opts, err := OptionsFromConfig(cfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	fmt.Println("You are now connected to local Ganache!")
+	if cfg.RPC.Backend == config.BackendSimulated {
+		return NewSimulatedSession(ctx, opts, signer)
+	}
 
-	privateKeyFromEnv := GoDotEnvVariable("DEPLOYER_PRIVATE_KEY")
+	RegisterChainsFromConfig(cfg.Chains)
+	return Dial(ctx, cfg.RPC.URLs, opts, signer)
+}
 
-	privateKey, err := crypto.HexToECDSA(privateKeyFromEnv)
+// NewReadOnlySession connects to the configured RPC endpoints without a
+// signer, for callers such as the tracker that only read from the chain.
+func NewReadOnlySession(ctx context.Context, cfg *config.Config) (*Session, error) {
+	opts, err := OptionsFromConfig(cfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+	if cfg.RPC.Backend == config.BackendSimulated {
+		return NewSimulatedSession(ctx, opts, nil)
 	}
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	fmt.Println("From address:", fromAddress.Hex())
-	balance, err := client.BalanceAt(context.Background(), fromAddress, nil)
+	RegisterChainsFromConfig(cfg.Chains)
+	return Dial(ctx, cfg.RPC.URLs, opts, nil)
+}
+
+// OptionsFromConfig maps the rpc and fees sections of the configuration.
+func OptionsFromConfig(cfg *config.Config) (Options, error) {
+	retry, err := RetryOptionsFromConfig(cfg.RPC)
 	if err != nil {
-		log.Fatal(err)
+		return Options{}, err
 	}
 
-	fmt.Println("The ETH balance of the account is:", balance)
-	fmt.Println("------------------------------------------------------------------------")
+	fees, err := FeeOptionsFromConfig(cfg.Fees)
+	if err != nil {
+		return Options{}, err
+	}
 
-	return client, chainID, fromAddress, privateKey //return contract owner's address as fromAddress
+	return Options{
+		Pool: PoolOptions{
+			Strategy:       cfg.RPC.Strategy,
+			HealthInterval: cfg.RPC.HealthInterval,
+			MaxBlockLag:    cfg.RPC.MaxBlockLag,
+		},
+		Retry:        retry,
+		Fees:         fees,
+		ConfirmChain: cfg.ConfirmChain,
+	}, nil
 }
 
-func GetClientForContractTx() *ethclient.Client {
-	client, err := ethclient.Dial(GoDotEnvVariable("GANACHE_URL"))
+// Dial opens a session over a pool of the given RPC endpoints. signer may be
+// nil for a read-only session.
+func Dial(ctx context.Context, urls []string, opts Options, signer Signer) (*Session, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no RPC endpoints configured")
+	}
+
+	pool, err := NewPool(ctx, urls, opts.Pool)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return client
-}
+	client := NewRetryBackend(pool, opts.Retry)
 
-// GetNextTransaction returns the next transaction in the pending transaction queue
-func GetNextTransaction() (*bind.TransactOpts, *ethclient.Client, common.Address, uint64, *big.Int, error) {
-	client, chainID, fromAddress, privateKey := Connection()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to get chain ID: %v", err)
+	}
 
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	session, err := open(ctx, client, chainID, LookupChain(chainID), opts, signer)
 	if err != nil {
-		log.Fatal(err)
+		pool.Close()
+		return nil, err
 	}
+	session.Pool = pool
+	return session, nil
+}
 
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+// open builds a session on client and reports the chain and account.
+func open(ctx context.Context, client Backend, chainID *big.Int, profile ChainProfile, opts Options, signer Signer) (*Session, error) {
+	fees, err := NewFeeOracle(client, opts.Fees)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+
+	fmt.Printf("You are now connected to %s (chain ID %s)!\n", profile.Name, chainID)
+	if profile.Production {
+		fmt.Println("WARNING: this is a production chain, transactions spend real funds")
 	}
 
-	// sign the transaction
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	session := &Session{
+		Client:         client,
+		ChainID:        chainID,
+		Signer:         signer,
+		Nonces:         NewNonceManager(client),
+		Fees:           fees,
+		Profile:        profile,
+		confirmedChain: opts.ConfirmChain,
+	}
+	if signer == nil {
+		return session, nil
+	}
+
+	session.From = signer.Address()
+	fmt.Println("From address:", session.From.Hex())
+
+	balance, err := client.BalanceAt(ctx, session.From, nil)
 	if err != nil {
-		return nil, client, fromAddress, nonce, gasPrice, err
+		return nil, fmt.Errorf("failed to get balance of %s: %v", session.From.Hex(), err)
 	}
 
-	return auth, client, fromAddress, nonce, gasPrice, nil
+	fmt.Println("The ETH balance of the account is:", balance)
+	fmt.Println("------------------------------------------------------------------------")
+
+	return session, nil //From is the contract owner's address
 }
 
-func GenerateNewWallet() common.Address {
-	privateKey, err := crypto.GenerateKey()
+// Close releases the RPC connections (or stops the simulated chain) and, for
+// remote signers, the connection to the signer.
+func (s *Session) Close() {
+	if s.Pool != nil {
+		s.Pool.Close()
+	}
+	if closer, ok := s.Client.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	if closer, ok := s.Signer.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// NextTransaction returns signing options for the next transaction of the
+// session account, with a nonce reserved from the session's nonce manager and
+// fees from the session's fee oracle filled in. If the transaction is then not
+// sent, the caller must hand the nonce back with TransactionFailed. On a
+// production chain it fails with a *ProductionChainError unless the session
+// was opened with that chain ID confirmed.
+func (s *Session) NextTransaction(ctx context.Context) (*bind.TransactOpts, error) {
+	if s.Signer == nil {
+		return nil, ErrReadOnlySession
+	}
+	if err := s.CheckWritable(); err != nil {
+		return nil, err
+	}
+
+	auth := &bind.TransactOpts{
+		From: s.From,
+		// sign the transaction
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.From {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.Signer.SignTx(ctx, tx, s.ChainID)
+		},
+		Value:   big.NewInt(0),
+		Context: ctx,
+	}
+
+	if err := s.Fees.Apply(ctx, auth); err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.Nonces.Next(ctx, s.From)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("Error casting public key to ECDSA")
+	return auth, nil
+}
+
+// OnSigned makes auth hand every transaction it signs to hook before the
+// transaction is sent, so its hash can be recorded first. An error from hook
+// stops the sending.
+func OnSigned(auth *bind.TransactOpts, hook func(*types.Transaction) error) {
+	sign := auth.Signer
+	auth.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signed, err := sign(address, tx)
+		if err != nil {
+			return nil, err
+		}
+		if err := hook(signed); err != nil {
+			return nil, err
+		}
+		return signed, nil
 	}
+}
 
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
+// CheckWritable reports whether the session may send transactions to its
+// chain. Commands call it before doing any work that ends in a transaction.
+func (s *Session) CheckWritable() error {
+	if s.Profile.Production && s.confirmedChain != s.Profile.ChainID {
+		return &ProductionChainError{Profile: s.Profile}
+	}
+	return nil
+}
 
-	return address
+// TransactionFailed releases the nonce reserved in auth after sending failed
+// with err, so that later transactions don't wait on a gap.
+func (s *Session) TransactionFailed(auth *bind.TransactOpts, err error) {
+	s.Nonces.Fail(s.From, auth.Nonce.Uint64(), err)
 }
@@ -0,0 +1,170 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
+
+// feeHistoryBlocks is how many recent blocks the priority fee is sampled from.
+const feeHistoryBlocks = 10
+
+// tipPercentiles maps the sampling strategies to the percentile of priority
+// fees paid in recent blocks.
+var tipPercentiles = map[string]float64{
+	config.FeeSlow:   10,
+	config.FeeNormal: 50,
+	config.FeeFast:   90,
+}
+
+// FeeBackend is the part of the client the fee oracle reads from.
+type FeeBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// FeeOptions selects how transactions are priced.
+type FeeOptions struct {
+	Strategy  string   // slow, normal, fast or fixed
+	MaxFeeCap *big.Int // upper bound for GasFeeCap (and GasPrice in legacy mode); required by fixed
+	TipCap    *big.Int // priority fee for the fixed strategy; sampled like normal when nil
+	Legacy    bool     // always send legacy transactions
+}
+
+// FeeOptionsFromConfig parses the fees section of the configuration.
+func FeeOptionsFromConfig(fees config.Fees) (FeeOptions, error) {
+	opts := FeeOptions{Strategy: fees.Strategy, Legacy: fees.Legacy}
+
+	var err error
+	if fees.MaxFeeGwei != "" {
+		if opts.MaxFeeCap, err = config.ParseGwei(fees.MaxFeeGwei); err != nil {
+			return FeeOptions{}, fmt.Errorf("invalid max fee: %v", err)
+		}
+	}
+	if fees.PriorityFeeGwei != "" {
+		if opts.TipCap, err = config.ParseGwei(fees.PriorityFeeGwei); err != nil {
+			return FeeOptions{}, fmt.Errorf("invalid priority fee: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// FeeOracle prices transactions. On chains with London active it produces
+// type-2 (EIP-1559) fees from eth_feeHistory; otherwise it falls back to a
+// legacy gas price.
+type FeeOracle struct {
+	backend FeeBackend
+	opts    FeeOptions
+}
+
+func NewFeeOracle(backend FeeBackend, opts FeeOptions) (*FeeOracle, error) {
+	switch opts.Strategy {
+	case "":
+		opts.Strategy = config.FeeNormal
+	case config.FeeSlow, config.FeeNormal, config.FeeFast:
+	case config.FeeFixed:
+		if opts.MaxFeeCap == nil {
+			return nil, errors.New("the fixed fee strategy needs a max fee")
+		}
+	default:
+		return nil, fmt.Errorf("unknown fee strategy %q", opts.Strategy)
+	}
+	return &FeeOracle{backend: backend, opts: opts}, nil
+}
+
+// Apply sets either GasPrice or GasTipCap and GasFeeCap on auth. It fails if
+// the max fee leaves a transaction unable to pay the current base fee, and
+// for EIP-1559 the priority fee on top, rather than send one that waits in
+// the pool until the base fee drops.
+func (o *FeeOracle) Apply(ctx context.Context, auth *bind.TransactOpts) error {
+	head, err := o.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest header: %v", err)
+	}
+
+	if o.opts.Legacy || head.BaseFee == nil {
+		if head.BaseFee != nil && o.opts.MaxFeeCap != nil && o.opts.MaxFeeCap.Cmp(head.BaseFee) < 0 {
+			return fmt.Errorf("max fee of %s wei is below the current base fee of %s wei", o.opts.MaxFeeCap, head.BaseFee)
+		}
+		gasPrice, err := o.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %v", err)
+		}
+		auth.GasPrice = o.capped(gasPrice)
+		auth.GasTipCap, auth.GasFeeCap = nil, nil
+		return nil
+	}
+
+	tip, err := o.tipCap(ctx)
+	if err != nil {
+		return err
+	}
+
+	var feeCap *big.Int
+	if o.opts.Strategy == config.FeeFixed {
+		feeCap = new(big.Int).Set(o.opts.MaxFeeCap)
+	} else {
+		// leave room for the base fee to double before the transaction is mined
+		feeCap = new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+		feeCap = o.capped(feeCap.Add(feeCap, tip))
+	}
+	if needed := new(big.Int).Add(head.BaseFee, tip); feeCap.Cmp(needed) < 0 {
+		return fmt.Errorf("max fee of %s wei is below the current base fee of %s wei plus the priority fee of %s wei", feeCap, head.BaseFee, tip)
+	}
+
+	auth.GasPrice = nil
+	auth.GasTipCap = tip
+	auth.GasFeeCap = feeCap
+	return nil
+}
+
+func (o *FeeOracle) tipCap(ctx context.Context) (*big.Int, error) {
+	if o.opts.Strategy == config.FeeFixed && o.opts.TipCap != nil {
+		return new(big.Int).Set(o.opts.TipCap), nil
+	}
+
+	percentile, ok := tipPercentiles[o.opts.Strategy]
+	if !ok {
+		percentile = tipPercentiles[config.FeeNormal]
+	}
+
+	history, err := o.backend.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{percentile})
+	if err == nil && len(history.Reward) > 0 {
+		rewards := make([]*big.Int, 0, len(history.Reward))
+		for _, reward := range history.Reward {
+			if len(reward) > 0 && reward[0] != nil {
+				rewards = append(rewards, reward[0])
+			}
+		}
+		if len(rewards) > 0 {
+			sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+			return new(big.Int).Set(rewards[len(rewards)/2]), nil
+		}
+	}
+
+	// nodes without eth_feeHistory (or with empty blocks) still suggest a tip
+	tip, tipErr := o.backend.SuggestGasTipCap(ctx)
+	if tipErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fee history: %v", err)
+		}
+		return nil, fmt.Errorf("failed to suggest priority fee: %v", tipErr)
+	}
+	return tip, nil
+}
+
+func (o *FeeOracle) capped(fee *big.Int) *big.Int {
+	if o.opts.MaxFeeCap != nil && fee.Cmp(o.opts.MaxFeeCap) > 0 {
+		return new(big.Int).Set(o.opts.MaxFeeCap)
+	}
+	return fee
+}
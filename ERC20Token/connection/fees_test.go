@@ -0,0 +1,207 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
+
+// errNoHistory is what a node without eth_feeHistory answers.
+var errNoHistory = errors.New("the method eth_feeHistory does not exist/is not available")
+
+// feeBackend is a simulated chain, which has no fee history of its own, whose
+// fee history, tip suggestion and gas price suggestion can be replaced.
+type feeBackend struct {
+	*backends.SimulatedBackend
+	history    *ethereum.FeeHistory // nil answers errNoHistory
+	historyErr error
+	tipErr     error
+	price      *big.Int
+}
+
+func (b *feeBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if b.history == nil && b.historyErr == nil {
+		return nil, errNoHistory
+	}
+	return b.history, b.historyErr
+}
+
+func (b *feeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if b.tipErr != nil {
+		return nil, b.tipErr
+	}
+	return b.SimulatedBackend.SuggestGasTipCap(ctx)
+}
+
+func (b *feeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if b.price != nil {
+		return b.price, nil
+	}
+	return b.SimulatedBackend.SuggestGasPrice(ctx)
+}
+
+func newFeeChain(t *testing.T) *backends.SimulatedBackend {
+	t.Helper()
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{}, 8_000_000)
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func rewards(tips ...int64) *ethereum.FeeHistory {
+	history := &ethereum.FeeHistory{}
+	for _, tip := range tips {
+		history.Reward = append(history.Reward, []*big.Int{big.NewInt(tip)})
+	}
+	return history
+}
+
+func TestFeeOracleTipCap(t *testing.T) {
+	backend := newFeeChain(t)
+	ctx := context.Background()
+	suggested, err := backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errDown := errors.New("node is down")
+	for _, test := range []struct {
+		name    string
+		backend *feeBackend
+		tip     *big.Int
+		errText string
+	}{
+		{"no fee history", &feeBackend{}, suggested, ""},
+		{"median of the history", &feeBackend{history: rewards(30, 10, 20)}, big.NewInt(20), ""},
+		{"blocks without rewards", &feeBackend{history: &ethereum.FeeHistory{Reward: [][]*big.Int{{}, {nil}}}}, suggested, ""},
+		{"empty history", &feeBackend{history: rewards()}, suggested, ""},
+		{"history fails", &feeBackend{historyErr: errDown}, suggested, ""},
+		{"neither", &feeBackend{tipErr: errDown}, nil, "failed to get fee history: " + errNoHistory.Error()},
+		{"empty history and no suggestion", &feeBackend{history: rewards(), tipErr: errDown}, nil, "failed to suggest priority fee: node is down"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.backend.SimulatedBackend = backend
+			oracle, err := NewFeeOracle(test.backend, FeeOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth := &bind.TransactOpts{}
+			err = oracle.Apply(ctx, auth)
+			if test.errText != "" {
+				if err == nil || err.Error() != test.errText {
+					t.Fatalf("error is %v, want %q", err, test.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+			feeCap.Add(feeCap, test.tip)
+			if auth.GasPrice != nil || auth.GasTipCap.Cmp(test.tip) != 0 || auth.GasFeeCap.Cmp(feeCap) != 0 {
+				t.Fatalf("fees are price %v, tip %v, cap %v; want tip %v, cap %v", auth.GasPrice, auth.GasTipCap, auth.GasFeeCap, test.tip, feeCap)
+			}
+		})
+	}
+}
+
+func TestFeeOracleOptions(t *testing.T) {
+	backend := newFeeChain(t)
+	ctx := context.Background()
+	price, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip, err := backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := head.BaseFee
+	plus := func(fees ...*big.Int) *big.Int {
+		sum := new(big.Int)
+		for _, fee := range fees {
+			sum.Add(sum, fee)
+		}
+		return sum
+	}
+	two := big.NewInt(2)
+
+	for _, test := range []struct {
+		name               string
+		opts               FeeOptions
+		suggestedPrice     *big.Int
+		price, tip, feeCap *big.Int
+		errText            string
+	}{
+		{"legacy", FeeOptions{Legacy: true}, nil, price, nil, nil, ""},
+		{"legacy capped", FeeOptions{Legacy: true, MaxFeeCap: plus(base, base)}, plus(base, base, base), plus(base, base), nil, nil, ""},
+		{"legacy cap below the base fee", FeeOptions{Legacy: true, MaxFeeCap: plus(base, big.NewInt(-1))}, nil, nil, nil, nil, "below the current base fee"},
+		{"capped", FeeOptions{MaxFeeCap: plus(base, tip)}, nil, nil, tip, plus(base, tip), ""},
+		{"cap below the base fee and tip", FeeOptions{MaxFeeCap: plus(base, tip, big.NewInt(-1))}, nil, nil, nil, nil, "below the current base fee"},
+		{"cap below the base fee", FeeOptions{MaxFeeCap: big.NewInt(5)}, nil, nil, nil, nil, "max fee of 5 wei is below"},
+		{"fixed", FeeOptions{Strategy: config.FeeFixed, MaxFeeCap: plus(base, base), TipCap: two}, nil, nil, two, plus(base, base), ""},
+		{"fixed tip above what the cap leaves", FeeOptions{Strategy: config.FeeFixed, MaxFeeCap: plus(base, two), TipCap: big.NewInt(3)}, nil, nil, nil, nil, "plus the priority fee of 3 wei"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			oracle, err := NewFeeOracle(&feeBackend{SimulatedBackend: backend, price: test.suggestedPrice}, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth := &bind.TransactOpts{}
+			err = oracle.Apply(ctx, auth)
+			if test.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errText) {
+					t.Fatalf("error is %v, want one containing %q", err, test.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fee := range []struct {
+				name      string
+				got, want *big.Int
+			}{
+				{"gas price", auth.GasPrice, test.price},
+				{"tip", auth.GasTipCap, test.tip},
+				{"fee cap", auth.GasFeeCap, test.feeCap},
+			} {
+				if (fee.got == nil) != (fee.want == nil) || fee.got != nil && fee.got.Cmp(fee.want) != 0 {
+					t.Errorf("%s is %v, want %v", fee.name, fee.got, fee.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFeeOracle(t *testing.T) {
+	for _, test := range []struct {
+		opts    FeeOptions
+		errText string
+	}{
+		{FeeOptions{}, ""},
+		{FeeOptions{Strategy: config.FeeFast}, ""},
+		{FeeOptions{Strategy: config.FeeFixed}, "needs a max fee"},
+		{FeeOptions{Strategy: "cheap"}, `unknown fee strategy "cheap"`},
+	} {
+		_, err := NewFeeOracle(nil, test.opts)
+		if test.errText == "" && err != nil || test.errText != "" && (err == nil || !strings.Contains(err.Error(), test.errText)) {
+			t.Errorf("%+v: error is %v, want %q", test.opts, err, test.errText)
+		}
+	}
+}
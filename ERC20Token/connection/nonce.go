@@ -0,0 +1,160 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceSource is the part of the client the nonce manager syncs from.
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out nonces per sender from a local counter, so
+// concurrent transactions from the same account never share a nonce. The
+// counter is seeded from the node's pending nonce and re-seeded whenever a
+// transaction fails in a way that leaves the local view out of date.
+type NonceManager struct {
+	source NonceSource
+
+	mu      sync.Mutex
+	senders map[common.Address]*senderNonces
+}
+
+type senderNonces struct {
+	synced   bool
+	next     uint64
+	released []uint64 // nonces below next that were never broadcast, handed out again first
+}
+
+func NewNonceManager(source NonceSource) *NonceManager {
+	return &NonceManager{
+		source:  source,
+		senders: make(map[common.Address]*senderNonces),
+	}
+}
+
+// Next reserves the next nonce for sender. Every reserved nonce must be
+// followed by exactly one call to Fail if the transaction could not be sent.
+func (m *NonceManager) Next(ctx context.Context, sender common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.senders[sender]
+	if s == nil {
+		s = &senderNonces{}
+		m.senders[sender] = s
+	}
+
+	if !s.synced {
+		pending, err := m.source.PendingNonceAt(ctx, sender)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get nonce: %v", err)
+		}
+		s.next = pending
+		s.released = nil
+		s.synced = true
+	}
+
+	// fill gaps left by failed transactions before moving the counter on
+	if len(s.released) > 0 {
+		nonce := s.released[0]
+		s.released = s.released[1:]
+		return nonce, nil
+	}
+
+	nonce := s.next
+	s.next++
+	return nonce, nil
+}
+
+// Fail returns a reserved nonce after sending the transaction failed. An
+// "already known" answer means the transaction is in the pool after all, so
+// the nonce stays used. Nonce errors mean our counter is wrong, and a send
+// that got no answer may or may not have reached the node, so in both cases
+// the sender is resynced from the chain. Any other error is the node
+// rejecting the transaction, which leaves the nonce free for reuse.
+func (m *NonceManager) Fail(sender common.Address, nonce uint64, err error) {
+	if isAlreadyKnown(err) {
+		return
+	}
+	if IsNonceError(err) || mayHaveBeenSent(err) {
+		m.Reset(sender)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.senders[sender]
+	if s == nil || !s.synced || nonce >= s.next {
+		return
+	}
+
+	if nonce == s.next-1 {
+		s.next--
+		// released nonces directly below can fold back into the counter too
+		for n := len(s.released); n > 0 && s.released[n-1] == s.next-1; n-- {
+			s.released = s.released[:n-1]
+			s.next--
+		}
+		return
+	}
+
+	i := sort.Search(len(s.released), func(i int) bool { return s.released[i] >= nonce })
+	if i < len(s.released) && s.released[i] == nonce {
+		return
+	}
+	s.released = append(s.released, 0)
+	copy(s.released[i+1:], s.released[i:])
+	s.released[i] = nonce
+}
+
+// Reset forgets everything known about sender; the next call to Next reads
+// the pending nonce from the node again. Use it after a transaction was
+// dropped from the pool or the account was used from somewhere else.
+func (m *NonceManager) Reset(sender common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.senders, sender)
+}
+
+// IsNonceError reports whether err is a node rejecting a transaction because
+// of its nonce.
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "nonce too high") ||
+		strings.Contains(msg, "replacement transaction underpriced") ||
+		strings.Contains(msg, "incorrect nonce") ||
+		strings.Contains(msg, "invalid transaction nonce") // the simulated backend
+}
+
+// isAlreadyKnown reports whether err is a node answering that it has the
+// transaction in its pool already, as it does to a resend of one that
+// reached it.
+func isAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already known")
+}
+
+// mayHaveBeenSent reports whether a send that failed with err may still have
+// reached a node: the node never answered, as opposed to answering with an
+// error.
+func mayHaveBeenSent(err error) bool {
+	var rpcErr rpc.Error
+	if err == nil || errors.As(err, &rpcErr) {
+		return false
+	}
+	return isTransportError(err) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
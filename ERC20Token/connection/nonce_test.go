@@ -0,0 +1,271 @@
+package connection
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newNonceChain starts a simulated chain with one funded account.
+func newNonceChain(t *testing.T) (*backends.SimulatedBackend, *ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{from: {Balance: funds}}, 8_000_000)
+	t.Cleanup(func() { backend.Close() })
+	return backend, key, from
+}
+
+// sendNonce sends a transfer of 1 wei to itself with nonce from key and mines
+// it.
+func sendNonce(backend *backends.SimulatedBackend, key *ecdsa.PrivateKey, nonce uint64) error {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := backend.Blockchain().Config().ChainID
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &from,
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(10 * params.GWei),
+	}), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		return err
+	}
+	if err := backend.SendTransaction(context.Background(), tx); err != nil {
+		return err
+	}
+	backend.Commit()
+	return nil
+}
+
+func next(t *testing.T, m *NonceManager, sender common.Address) uint64 {
+	t.Helper()
+	nonce, err := m.Next(context.Background(), sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nonce
+}
+
+func TestNonceManagerStartsAtPendingNonce(t *testing.T) {
+	backend, key, from := newNonceChain(t)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := sendNonce(backend, key, nonce); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewNonceManager(backend)
+	for want := uint64(2); want < 5; want++ {
+		if got := next(t, m, from); got != want {
+			t.Fatalf("nonce is %d, want %d", got, want)
+		}
+	}
+}
+
+// nodeError is a node answering a request with an error.
+type nodeError string
+
+func (e nodeError) Error() string  { return string(e) }
+func (e nodeError) ErrorCode() int { return -32000 }
+
+func TestNonceManagerReusesReleasedNonces(t *testing.T) {
+	errSend := nodeError("insufficient funds for gas * price + value")
+	for _, test := range []struct {
+		name   string
+		failed []uint64 // of the reserved 0 to 3
+		want   []uint64
+	}{
+		{"last", []uint64{3}, []uint64{3, 4}},
+		{"middle", []uint64{1}, []uint64{1, 4}},
+		{"gap then last", []uint64{1, 3}, []uint64{1, 3, 4}},
+		{"folding back", []uint64{2, 3}, []uint64{2, 3, 4}},
+		{"twice", []uint64{1, 1}, []uint64{1, 4}},
+		{"never reserved", []uint64{7}, []uint64{4}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backend, _, from := newNonceChain(t)
+			m := NewNonceManager(backend)
+			for i := 0; i < 4; i++ {
+				next(t, m, from)
+			}
+			for _, nonce := range test.failed {
+				m.Fail(from, nonce, errSend)
+			}
+			for _, want := range test.want {
+				if got := next(t, m, from); got != want {
+					t.Fatalf("nonce is %d, want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+// Nonces 0 to 2 are reserved and 0 and 1 reached the chain, but sending 1
+// failed; what happens to its nonce depends on how.
+func TestNonceManagerFail(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		err  error
+		want uint64
+	}{
+		{"rejected by the node", nodeError("insufficient funds for gas * price + value"), 1},
+		{"already known", nodeError("already known"), 3},
+		{"timed out", context.DeadlineExceeded, 2},
+		{"connection lost", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, 2},
+		{"nonce error", nodeError("nonce too low"), 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backend, key, from := newNonceChain(t)
+			m := NewNonceManager(backend)
+			for i := 0; i < 3; i++ {
+				next(t, m, from)
+			}
+			for nonce := uint64(0); nonce < 2; nonce++ {
+				if err := sendNonce(backend, key, nonce); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m.Fail(from, 1, test.err)
+			if got := next(t, m, from); got != test.want {
+				t.Fatalf("nonce is %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+// The account sends from somewhere else: the node's rejection resyncs.
+func TestNonceManagerResyncsOnNonceError(t *testing.T) {
+	backend, key, from := newNonceChain(t)
+	m := NewNonceManager(backend)
+
+	nonce := next(t, m, from)
+	if err := sendNonce(backend, key, 0); err != nil {
+		t.Fatal(err)
+	}
+	err := sendNonce(backend, key, nonce)
+	if !IsNonceError(err) {
+		t.Fatalf("sending a used nonce gave %v, not a nonce error", err)
+	}
+	m.Fail(from, nonce, err)
+	if got := next(t, m, from); got != 1 {
+		t.Fatalf("nonce after resync is %d, want 1", got)
+	}
+}
+
+// A nonce sent ahead of one that never made it leaves a gap the node refuses.
+func TestNonceManagerResyncsAfterGap(t *testing.T) {
+	backend, key, from := newNonceChain(t)
+	m := NewNonceManager(backend)
+
+	next(t, m, from) // 0, lost before it was sent
+	nonce := next(t, m, from)
+	err := sendNonce(backend, key, nonce)
+	if !IsNonceError(err) {
+		t.Fatalf("sending across a gap gave %v, not a nonce error", err)
+	}
+	m.Fail(from, nonce, err)
+	if got := next(t, m, from); got != 0 {
+		t.Fatalf("nonce after resync is %d, want 0", got)
+	}
+}
+
+// A reorg drops a mined transaction, so its nonce is free again on the chain.
+func TestNonceManagerResyncsAfterReorg(t *testing.T) {
+	backend, key, from := newNonceChain(t)
+	m := NewNonceManager(backend)
+	genesis := backend.Blockchain().Genesis().Hash()
+
+	nonce := next(t, m, from)
+	if err := sendNonce(backend, key, nonce); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Fork(context.Background(), genesis); err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+	backend.Commit()
+
+	nonce = next(t, m, from)
+	if nonce != 1 {
+		t.Fatalf("nonce is %d, want 1", nonce)
+	}
+	err := sendNonce(backend, key, nonce)
+	if !IsNonceError(err) {
+		t.Fatalf("sending after the reorg gave %v, not a nonce error", err)
+	}
+	m.Fail(from, nonce, err)
+	if got := next(t, m, from); got != 0 {
+		t.Fatalf("nonce after resync is %d, want 0", got)
+	}
+}
+
+func TestNonceManagerConcurrentNext(t *testing.T) {
+	backend, _, from := newNonceChain(t)
+	m := NewNonceManager(backend)
+
+	const n = 50
+	nonces := make(chan uint64, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.Next(context.Background(), from)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			nonces <- nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[uint64]bool)
+	for nonce := range nonces {
+		if seen[nonce] || nonce >= n {
+			t.Fatalf("nonce %d handed out twice or out of range", nonce)
+		}
+		seen[nonce] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("%d nonces handed out, want %d", len(seen), n)
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("nonce too low"), true},
+		{errors.New("Nonce too high"), true},
+		{errors.New("already known"), false},
+		{errors.New("replacement transaction underpriced"), true},
+		{errors.New("incorrect nonce"), true},
+		{errors.New("invalid transaction nonce: got 1, want 0"), true},
+		{errors.New("insufficient funds for gas * price + value"), false},
+	} {
+		if got := IsNonceError(test.err); got != test.want {
+			t.Errorf("IsNonceError(%v) is %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,524 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Pool strategies.
+const (
+	// StrategyFailover sends every call to the first healthy endpoint in
+	// configuration order.
+	StrategyFailover = "failover"
+	// StrategyRoundRobin spreads calls over all healthy endpoints.
+	StrategyRoundRobin = "round-robin"
+)
+
+// PoolOptions tunes a Pool. Zero values fall back to the defaults below.
+type PoolOptions struct {
+	Strategy       string
+	HealthInterval time.Duration // default 10s
+	MaxBlockLag    uint64        // blocks an endpoint may trail the highest one, default 5
+}
+
+// Pool keeps one client per RPC endpoint for the lifetime of the process and
+// routes every call to a healthy one. Endpoints are health-checked in the
+// background: one that reports a different chain ID or trails the highest
+// block by more than MaxBlockLag is taken out of rotation until it recovers.
+// Calls that fail with a transport error are retried on the next endpoint;
+// errors returned by the node itself (reverts, nonce errors) are not.
+//
+// Pool implements bind.ContractBackend and bind.DeployBackend, so it can be
+// handed to the generated contract bindings directly.
+type Pool struct {
+	opts      PoolOptions
+	chainID   *big.Int
+	endpoints []*endpoint
+	counter   atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type endpoint struct {
+	url string
+
+	mu         sync.RWMutex
+	client     *ethclient.Client
+	healthy    bool
+	wrongChain bool // never used, not even as a last resort
+	height     uint64
+	lastErr    error
+}
+
+var errWrongChain = errors.New("wrong chain")
+
+// EndpointStatus is a point-in-time view of one pool endpoint.
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+	Height  uint64
+	Err     error
+}
+
+// NewPool dials every url, pins the pool to the chain ID reported by the first
+// endpoint that answers and starts the background health checks. It fails only
+// if no endpoint can be reached at all.
+func NewPool(ctx context.Context, urls []string, opts PoolOptions) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no RPC endpoints configured")
+	}
+	switch opts.Strategy {
+	case "":
+		opts.Strategy = StrategyFailover
+	case StrategyFailover, StrategyRoundRobin:
+	default:
+		return nil, fmt.Errorf("unknown pool strategy %q", opts.Strategy)
+	}
+	if opts.HealthInterval <= 0 {
+		opts.HealthInterval = 10 * time.Second
+	}
+	if opts.MaxBlockLag == 0 {
+		opts.MaxBlockLag = 5
+	}
+
+	p := &Pool{
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	var errs []error
+	for _, url := range urls {
+		ep := &endpoint{url: url}
+		p.endpoints = append(p.endpoints, ep)
+
+		client, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", url, err))
+			ep.lastErr = err
+			continue
+		}
+		ep.client = client
+
+		if p.chainID == nil {
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", url, err))
+				ep.lastErr = err
+				continue
+			}
+			p.chainID = chainID
+		}
+	}
+	if p.chainID == nil {
+		p.closeClients()
+		return nil, fmt.Errorf("no RPC endpoint reachable: %v", errors.Join(errs...))
+	}
+
+	p.checkHealth(ctx)
+	go p.healthLoop()
+
+	return p, nil
+}
+
+// Close stops the health checks and closes every client.
+func (p *Pool) Close() {
+	close(p.stop)
+	<-p.done
+	p.closeClients()
+}
+
+func (p *Pool) closeClients() {
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.client != nil {
+			ep.client.Close()
+			ep.client = nil
+		}
+		ep.mu.Unlock()
+	}
+}
+
+// Status reports the state of every endpoint as of the last health check.
+func (p *Pool) Status() []EndpointStatus {
+	status := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ep.mu.RLock()
+		status = append(status, EndpointStatus{URL: ep.url, Healthy: ep.healthy, Height: ep.height, Err: ep.lastErr})
+		ep.mu.RUnlock()
+	}
+	return status
+}
+
+func (p *Pool) healthLoop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.opts.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.opts.HealthInterval)
+			p.checkHealth(ctx)
+			cancel()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// checkHealth probes every endpoint concurrently, then marks those on the
+// wrong chain or too far behind the best block as unhealthy.
+func (p *Pool) checkHealth(ctx context.Context) {
+	heights := make([]uint64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			heights[i], errs[i] = p.probe(ctx, ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range p.endpoints {
+		if errs[i] == nil && heights[i] > best {
+			best = heights[i]
+		}
+	}
+
+	for i, ep := range p.endpoints {
+		err := errs[i]
+		if err == nil && best-heights[i] > p.opts.MaxBlockLag {
+			err = fmt.Errorf("block %d trails best block %d", heights[i], best)
+		}
+		p.setHealth(ep, heights[i], err)
+	}
+}
+
+func (p *Pool) probe(ctx context.Context, ep *endpoint) (uint64, error) {
+	ep.mu.RLock()
+	client := ep.client
+	ep.mu.RUnlock()
+
+	if client == nil {
+		var err error
+		if client, err = ethclient.DialContext(ctx, ep.url); err != nil {
+			return 0, err
+		}
+		ep.mu.Lock()
+		ep.client = client
+		ep.mu.Unlock()
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if chainID.Cmp(p.chainID) != 0 {
+		return 0, fmt.Errorf("%w: chain ID %s does not match pool chain ID %s", errWrongChain, chainID, p.chainID)
+	}
+
+	return client.BlockNumber(ctx)
+}
+
+func (p *Pool) setHealth(ep *endpoint, height uint64, err error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	healthy := err == nil
+	if healthy != ep.healthy {
+		if healthy {
+			log.Printf("RPC endpoint %s is healthy at block %d", ep.url, height)
+		} else {
+			log.Printf("RPC endpoint %s is unhealthy: %v", ep.url, err)
+		}
+	}
+	ep.healthy = healthy
+	ep.wrongChain = errors.Is(err, errWrongChain)
+	ep.lastErr = err
+	if height > 0 {
+		ep.height = height
+	}
+}
+
+// candidates lists the endpoints to try for one call: healthy ones first,
+// in the order the strategy dictates, then the rest as a last resort.
+func (p *Pool) candidates() []*endpoint {
+	start := 0
+	if p.opts.Strategy == StrategyRoundRobin {
+		start = int(p.counter.Add(1) % uint64(len(p.endpoints)))
+	}
+
+	var healthy, unhealthy []*endpoint
+	for i := range p.endpoints {
+		ep := p.endpoints[(start+i)%len(p.endpoints)]
+		ep.mu.RLock()
+		ok, usable := ep.healthy, ep.client != nil && !ep.wrongChain
+		ep.mu.RUnlock()
+
+		switch {
+		case ok:
+			healthy = append(healthy, ep)
+		case usable:
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// do runs fn against the pool's endpoints until one succeeds or fails with an
+// error that another endpoint would report just the same.
+func (p *Pool) do(ctx context.Context, fn func(*ethclient.Client) error) error {
+	var lastErr error
+	for _, ep := range p.candidates() {
+		ep.mu.RLock()
+		client := ep.client
+		ep.mu.RUnlock()
+		if client == nil {
+			continue
+		}
+
+		err := fn(client)
+		if err == nil || !isTransportError(err) || ctx.Err() != nil {
+			return err
+		}
+		p.setHealth(ep, 0, err)
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no RPC endpoint available")
+	}
+	return lastErr
+}
+
+// isTransportError reports whether err came from failing to reach a node
+// rather than from the node answering the request with an error: a network
+// error (dialing, reading or writing), a connection closed in the middle of
+// a response, or an HTTP status of an endpoint that is down or overloaded.
+// Anything else, such as a response that can't be decoded, is not.
+func isTransportError(err error) bool {
+	var rpcErr rpc.Error
+	if err == nil || errors.As(err, &rpcErr) {
+		return false
+	}
+	// context errors are net.Errors too, but say nothing about the node
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var httpErr rpc.HTTPError
+	return errors.As(err, &httpErr) && (httpErr.StatusCode == 429 || httpErr.StatusCode >= 500)
+}
+
+func (p *Pool) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(p.chainID), nil
+}
+
+func (p *Pool) BlockNumber(ctx context.Context) (height uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		height, err = c.BlockNumber(ctx)
+		return err
+	})
+	return height, err
+}
+
+func (p *Pool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (balance *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		balance, err = c.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+func (p *Pool) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		header, err = c.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (p *Pool) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		code, err = c.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (p *Pool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		result, err = c.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		code, err = c.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (p *Pool) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		nonce, err = c.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (p *Pool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		nonce, err = c.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return nonce, err
+}
+
+func (p *Pool) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		price, err = c.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (p *Pool) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		tip, err = c.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (p *Pool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		gas, err = c.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction may reach a second endpoint after the first one accepted
+// the transaction but failed to answer. The signed transaction is identical,
+// so the second endpoint answers "already known" instead of spending twice,
+// and that counts as sent.
+func (p *Pool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	resend := false
+	return p.do(ctx, func(c *ethclient.Client) error {
+		err := c.SendTransaction(ctx, tx)
+		if resend && isAlreadyKnown(err) {
+			return nil
+		}
+		resend = true
+		return err
+	})
+}
+
+func (p *Pool) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		tx, isPending, err = c.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (p *Pool) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		receipt, err = c.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (p *Pool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		logs, err = c.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+func (p *Pool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	err = p.subscribe(ctx, func(c *ethclient.Client) error {
+		sub, err = c.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return sub, err
+}
+
+func (p *Pool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (sub ethereum.Subscription, err error) {
+	err = p.subscribe(ctx, func(c *ethclient.Client) error {
+		sub, err = c.SubscribeNewHead(ctx, ch)
+		return err
+	})
+	return sub, err
+}
+
+// subscribe is do for subscriptions: endpoints that can't push notifications
+// (plain HTTP) are skipped without being marked unhealthy, so a ws:// endpoint
+// further down the list is used. The subscription stays on the endpoint it was
+// made on; callers resubscribe when it drops.
+func (p *Pool) subscribe(ctx context.Context, fn func(*ethclient.Client) error) error {
+	var lastErr error
+	for _, ep := range p.candidates() {
+		ep.mu.RLock()
+		client := ep.client
+		ep.mu.RUnlock()
+		if client == nil {
+			continue
+		}
+
+		err := fn(client)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, rpc.ErrNotificationsUnsupported):
+		case isTransportError(err) && ctx.Err() == nil:
+			p.setHealth(ep, 0, err)
+		default:
+			return err
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no RPC endpoint available")
+	}
+	return lastErr
+}
+
+func (p *Pool) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (history *ethereum.FeeHistory, err error) {
+	err = p.do(ctx, func(c *ethclient.Client) error {
+		history, err = c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return history, err
+}
@@ -0,0 +1,204 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNode answers the few eth methods the pool tests call, at a height
+// the test sets. While down, its endpoint answers 503 to everything.
+type fakeNode struct {
+	chainID int64
+	height  atomic.Uint64
+	down    atomic.Bool
+
+	sendErr  error // eth_sendRawTransaction's answer
+	balances atomic.Int64
+	sends    atomic.Int64
+}
+
+func (n *fakeNode) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(n.chainID))
+}
+
+func (n *fakeNode) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(n.height.Load())
+}
+
+func (n *fakeNode) GetBalance(account common.Address, block string) *hexutil.Big {
+	n.balances.Add(1)
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (n *fakeNode) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	n.sends.Add(1)
+	return common.Hash{}, n.sendErr
+}
+
+// startNode serves a fakeNode over HTTP and returns it with its URL.
+func startNode(t *testing.T, chainID int64, height uint64) (*fakeNode, string) {
+	t.Helper()
+	node := &fakeNode{chainID: chainID}
+	node.height.Store(height)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if node.down.Load() {
+			http.Error(w, "node is down", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		server.Stop()
+	})
+	return node, srv.URL
+}
+
+func newPool(t *testing.T, urls []string, strategy string) *Pool {
+	t.Helper()
+	// the tests run the health checks themselves
+	p, err := NewPool(context.Background(), urls, PoolOptions{Strategy: strategy, HealthInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+func checkHealthy(t *testing.T, p *Pool, want ...bool) {
+	t.Helper()
+	for i, status := range p.Status() {
+		if status.Healthy != want[i] {
+			t.Errorf("endpoint %d is healthy: %v (%v), want %v", i+1, status.Healthy, status.Err, want[i])
+		}
+	}
+}
+
+// An endpoint on another chain, trailing the best block or down is taken
+// out of rotation, and put back once it recovers.
+func TestPoolHealthChecks(t *testing.T) {
+	ctx := context.Background()
+	_, best := startNode(t, 1, 100)
+	behind, trailing := startNode(t, 1, 90)
+	_, otherChain := startNode(t, 2, 100)
+	flaky, flakyURL := startNode(t, 1, 100)
+	p := newPool(t, []string{best, trailing, otherChain, flakyURL}, StrategyFailover)
+	checkHealthy(t, p, true, false, false, true)
+	if err := p.Status()[1].Err; err == nil || !strings.Contains(err.Error(), "trails best block 100") {
+		t.Errorf("trailing endpoint's error is %v, want it trailing", err)
+	}
+	if err := p.Status()[2].Err; !errors.Is(err, errWrongChain) {
+		t.Errorf("other chain's error is %v, want errWrongChain", err)
+	}
+
+	behind.height.Store(98)
+	flaky.down.Store(true)
+	p.checkHealth(ctx)
+	checkHealthy(t, p, true, true, false, false)
+
+	flaky.down.Store(false)
+	p.checkHealth(ctx)
+	checkHealthy(t, p, true, true, false, true)
+}
+
+// A call that can't reach an endpoint moves on to the next and marks the
+// first unhealthy, so the calls after it start with the next one; an error
+// the node answers with is returned as it is.
+func TestPoolFailover(t *testing.T) {
+	ctx := context.Background()
+	first, firstURL := startNode(t, 1, 100)
+	second, secondURL := startNode(t, 1, 100)
+	p := newPool(t, []string{firstURL, secondURL}, StrategyFailover)
+
+	if _, err := p.BalanceAt(ctx, common.Address{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	first.down.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, err := p.BalanceAt(ctx, common.Address{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.balances.Load() != 1 || second.balances.Load() != 3 {
+		t.Errorf("endpoints answered %d and %d calls, want 1 and 3", first.balances.Load(), second.balances.Load())
+	}
+	checkHealthy(t, p, false, true)
+
+	second.sendErr = errors.New("nonce too low")
+	if err := p.SendTransaction(ctx, types.NewTx(&types.LegacyTx{})); err == nil || err.Error() != "nonce too low" {
+		t.Errorf("error is %v, want the node's", err)
+	}
+	if first.sends.Load() != 0 || second.sends.Load() != 1 {
+		t.Errorf("endpoints got %d and %d sends, want 0 and 1", first.sends.Load(), second.sends.Load())
+	}
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	var nodes []*fakeNode
+	var urls []string
+	for i := 0; i < 3; i++ {
+		node, url := startNode(t, 1, 100)
+		nodes, urls = append(nodes, node), append(urls, url)
+	}
+	p := newPool(t, urls, StrategyRoundRobin)
+
+	for i := 0; i < 6; i++ {
+		if _, err := p.BalanceAt(ctx, common.Address{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, node := range nodes {
+		if calls := node.balances.Load(); calls != 2 {
+			t.Errorf("endpoint %d answered %d of 6 calls, want 2", i+1, calls)
+		}
+	}
+}
+
+// A transaction the next endpoint already knows after the first failed to
+// answer was sent; one known to the first endpoint asked was sent before.
+func TestPoolSendTransaction(t *testing.T) {
+	known := errors.New("already known")
+	for _, test := range []struct {
+		name      string
+		firstDown bool
+		firstErr  error
+		secondErr error
+		err       string
+	}{
+		{"sent", false, nil, nil, ""},
+		{"already known on failover", true, nil, known, ""},
+		{"already known at once", false, known, nil, "already known"},
+		{"rejected on failover", true, nil, errors.New("insufficient funds"), "insufficient funds"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			first, firstURL := startNode(t, 1, 100)
+			second, secondURL := startNode(t, 1, 100)
+			first.sendErr, second.sendErr = test.firstErr, test.secondErr
+			p := newPool(t, []string{firstURL, secondURL}, StrategyFailover)
+			first.down.Store(test.firstDown)
+
+			err := p.SendTransaction(context.Background(), types.NewTx(&types.LegacyTx{}))
+			if test.err == "" && err != nil || test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Fatalf("error is %v, want %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"golang.org/x/time/rate"
+)
+
+// RetryPolicy controls how one RPC method is retried. Zero fields in a
+// per-method policy inherit from the default policy.
+type RetryPolicy struct {
+	MaxAttempts int           // attempts in total, including the first
+	BaseDelay   time.Duration // backoff before the second attempt, doubled after every failure
+	MaxDelay    time.Duration // upper bound for the backoff
+	Timeout     time.Duration // deadline for a single attempt, 0 for none
+}
+
+// RetryOptions configures a RetryBackend.
+type RetryOptions struct {
+	Policy            RetryPolicy
+	Methods           map[string]RetryPolicy // keyed by method name, e.g. "SendTransaction"
+	RequestsPerSecond float64                // 0 disables the rate limit
+	Burst             int                    // defaults to one second worth of requests
+}
+
+// retryMethods lists the methods of RetryBackend with the policy they get on
+// top of the default one. Sending is retried less because every attempt
+// reaches every endpoint of the pool; subscriptions are retried less so the
+// tracker falls back to polling without a long wait.
+var retryMethods = map[string]RetryPolicy{
+	"ChainID":             {},
+	"BlockNumber":         {},
+	"BalanceAt":           {},
+	"HeaderByNumber":      {},
+	"CodeAt":              {},
+	"CallContract":        {},
+	"PendingCodeAt":       {},
+	"PendingNonceAt":      {},
+	"NonceAt":             {},
+	"SuggestGasPrice":     {},
+	"SuggestGasTipCap":    {},
+	"EstimateGas":         {},
+	"SendTransaction":     {MaxAttempts: 3},
+	"TransactionByHash":   {},
+	"TransactionReceipt":  {},
+	"FilterLogs":          {},
+	"SubscribeFilterLogs": {MaxAttempts: 2},
+	"SubscribeNewHead":    {MaxAttempts: 2},
+	"FeeHistory":          {},
+}
+
+// RetryOptionsFromConfig maps the retry settings of the rpc section.
+func RetryOptionsFromConfig(rpcCfg config.RPC) (RetryOptions, error) {
+	opts := RetryOptions{
+		Policy:            RetryPolicy(rpcCfg.Retry),
+		Methods:           make(map[string]RetryPolicy),
+		RequestsPerSecond: rpcCfg.RateLimit,
+		Burst:             rpcCfg.RateBurst,
+	}
+	for method, policy := range rpcCfg.MethodRetry {
+		if _, ok := retryMethods[method]; !ok {
+			return RetryOptions{}, fmt.Errorf("rpc.method_retry: unknown method %q", method)
+		}
+		opts.Methods[method] = RetryPolicy(policy)
+	}
+	return opts, nil
+}
+
+// RetryBackend wraps a Backend so that every call waits for the rate limiter,
+// runs under a per-attempt deadline and is retried with exponential backoff
+// and jitter when it fails with a transient error: a node that can't be
+// reached, an attempt that timed out, or a node asking us to slow down.
+// Errors the node answers with (reverts, nonce errors, unknown transactions)
+// are returned straight away.
+type RetryBackend struct {
+	backend  Backend
+	policies map[string]RetryPolicy
+	limiter  *rate.Limiter
+}
+
+func NewRetryBackend(backend Backend, opts RetryOptions) *RetryBackend {
+	base := opts.Policy
+	if base.MaxAttempts <= 0 {
+		base.MaxAttempts = 1
+	}
+	if base.BaseDelay <= 0 {
+		base.BaseDelay = 200 * time.Millisecond
+	}
+	if base.MaxDelay < base.BaseDelay {
+		base.MaxDelay = base.BaseDelay
+	}
+
+	r := &RetryBackend{backend: backend, policies: make(map[string]RetryPolicy)}
+	for method, builtin := range retryMethods {
+		policy := mergePolicy(base, builtin)
+		if builtin.MaxAttempts > base.MaxAttempts {
+			policy.MaxAttempts = base.MaxAttempts
+		}
+		r.policies[method] = mergePolicy(policy, opts.Methods[method])
+	}
+
+	if opts.RequestsPerSecond > 0 {
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = int(opts.RequestsPerSecond)
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		r.limiter = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), burst)
+	}
+	return r
+}
+
+// mergePolicy returns base with the non-zero fields of override applied.
+func mergePolicy(base, override RetryPolicy) RetryPolicy {
+	if override.MaxAttempts > 0 {
+		base.MaxAttempts = override.MaxAttempts
+	}
+	if override.BaseDelay > 0 {
+		base.BaseDelay = override.BaseDelay
+	}
+	if override.MaxDelay > 0 {
+		base.MaxDelay = override.MaxDelay
+	}
+	if override.Timeout > 0 {
+		base.Timeout = override.Timeout
+	}
+	return base
+}
+
+// call runs fn under the policy of method until it succeeds, fails with an
+// error that isn't worth retrying, runs out of attempts or ctx is done.
+func (r *RetryBackend) call(ctx context.Context, method string, fn func(ctx context.Context, attempt int) error) error {
+	policy := r.policies[method]
+
+	for attempt := 1; ; attempt++ {
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		}
+		err := fn(attemptCtx, attempt)
+		cancel()
+
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		delay := backoff(policy, attempt)
+		log.Printf("RPC %s failed (attempt %d of %d), retrying in %v: %v", method, attempt, policy.MaxAttempts, delay.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// backoff doubles the base delay for every failed attempt, caps it, and picks
+// a random delay between half of that and all of it so that clients that
+// failed together don't retry together.
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.MaxDelay
+	if shift := attempt - 1; shift < 32 && policy.BaseDelay<<shift < policy.MaxDelay {
+		delay = policy.BaseDelay << shift
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isRetryable reports whether err is transient: the node couldn't be reached,
+// the attempt ran into its deadline, or the node is rate limiting us.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the "limit exceeded" code of EIP-1474
+		if rpcErr.ErrorCode() == -32005 {
+			return true
+		}
+		msg := strings.ToLower(rpcErr.Error())
+		return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests")
+	}
+
+	return isTransportError(err)
+}
+
+func (r *RetryBackend) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = r.call(ctx, "ChainID", func(ctx context.Context, _ int) error {
+		chainID, err = r.backend.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (r *RetryBackend) BlockNumber(ctx context.Context) (height uint64, err error) {
+	err = r.call(ctx, "BlockNumber", func(ctx context.Context, _ int) error {
+		height, err = r.backend.BlockNumber(ctx)
+		return err
+	})
+	return height, err
+}
+
+func (r *RetryBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (balance *big.Int, err error) {
+	err = r.call(ctx, "BalanceAt", func(ctx context.Context, _ int) error {
+		balance, err = r.backend.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+func (r *RetryBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = r.call(ctx, "HeaderByNumber", func(ctx context.Context, _ int) error {
+		header, err = r.backend.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (r *RetryBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = r.call(ctx, "CodeAt", func(ctx context.Context, _ int) error {
+		code, err = r.backend.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (r *RetryBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = r.call(ctx, "CallContract", func(ctx context.Context, _ int) error {
+		result, err = r.backend.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (r *RetryBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = r.call(ctx, "PendingCodeAt", func(ctx context.Context, _ int) error {
+		code, err = r.backend.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (r *RetryBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = r.call(ctx, "PendingNonceAt", func(ctx context.Context, _ int) error {
+		nonce, err = r.backend.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (r *RetryBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = r.call(ctx, "NonceAt", func(ctx context.Context, _ int) error {
+		nonce, err = r.backend.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return nonce, err
+}
+
+func (r *RetryBackend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = r.call(ctx, "SuggestGasPrice", func(ctx context.Context, _ int) error {
+		price, err = r.backend.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (r *RetryBackend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = r.call(ctx, "SuggestGasTipCap", func(ctx context.Context, _ int) error {
+		tip, err = r.backend.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (r *RetryBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = r.call(ctx, "EstimateGas", func(ctx context.Context, _ int) error {
+		gas, err = r.backend.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction treats "already known" on a retry as success: the attempt
+// that timed out did reach the node.
+func (r *RetryBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return r.call(ctx, "SendTransaction", func(ctx context.Context, attempt int) error {
+		err := r.backend.SendTransaction(ctx, tx)
+		if attempt > 1 && isAlreadyKnown(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (r *RetryBackend) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = r.call(ctx, "TransactionByHash", func(ctx context.Context, _ int) error {
+		tx, isPending, err = r.backend.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (r *RetryBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = r.call(ctx, "TransactionReceipt", func(ctx context.Context, _ int) error {
+		receipt, err = r.backend.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (r *RetryBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = r.call(ctx, "FilterLogs", func(ctx context.Context, _ int) error {
+		logs, err = r.backend.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+func (r *RetryBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	err = r.call(ctx, "SubscribeFilterLogs", func(ctx context.Context, _ int) error {
+		sub, err = r.backend.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return sub, err
+}
+
+func (r *RetryBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (sub ethereum.Subscription, err error) {
+	err = r.call(ctx, "SubscribeNewHead", func(ctx context.Context, _ int) error {
+		sub, err = r.backend.SubscribeNewHead(ctx, ch)
+		return err
+	})
+	return sub, err
+}
+
+func (r *RetryBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (history *ethereum.FeeHistory, err error) {
+	err = r.call(ctx, "FeeHistory", func(ctx context.Context, _ int) error {
+		history, err = r.backend.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+		return err
+	})
+	return history, err
+}
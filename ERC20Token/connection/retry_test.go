@@ -0,0 +1,105 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// rpcError is a node answering a request with an error of another code than
+// nodeError's.
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIsRetryable(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	for _, test := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"deadline", context.DeadlineExceeded, true},
+		{"deadline of an http request", &url.Error{Op: "Post", URL: "http://node", Err: context.DeadlineExceeded}, true},
+		{"canceled", context.Canceled, false},
+		{"dial refused", refused, true},
+		{"dial refused, wrapped", fmt.Errorf("failed to call: %w", refused), true},
+		{"connection closed mid-response", &url.Error{Op: "Post", URL: "http://node", Err: io.EOF}, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"http 429", rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{"http 503", rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{"http 401", rpc.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}, false},
+		{"limit exceeded code", rpcError{-32005, "limit exceeded"}, true},
+		{"rate limit message", nodeError("daily request count exceeded, request rate limited"), true},
+		{"revert", rpcError{3, "execution reverted"}, false},
+		{"nonce too low", nodeError("nonce too low"), false},
+		{"not found", ethereum.NotFound, false},
+		{"notifications unsupported", rpc.ErrNotificationsUnsupported, false},
+		{"undecodable response", errors.New("invalid character '<' looking for beginning of value"), false},
+	} {
+		if got := isRetryable(test.err); got != test.want {
+			t.Errorf("%s: retryable is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// sendBackend answers SendTransaction with errs in turn, then with nil.
+type sendBackend struct {
+	Backend
+	errs  []error
+	calls int
+}
+
+func (b *sendBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.calls++
+	if b.calls > len(b.errs) {
+		return nil
+	}
+	return b.errs[b.calls-1]
+}
+
+func TestRetrySendTransaction(t *testing.T) {
+	known := nodeError("already known")
+	for _, test := range []struct {
+		name  string
+		errs  []error
+		calls int
+		err   error
+	}{
+		{"sent", nil, 1, nil},
+		{"timed out, then sent", []error{context.DeadlineExceeded}, 2, nil},
+		// the attempt that timed out reached the node
+		{"timed out, then already known", []error{context.DeadlineExceeded, known}, 2, nil},
+		// known before this send: not ours to call a success
+		{"already known at once", []error{known}, 1, known},
+		{"nonce too low", []error{nodeError("nonce too low")}, 1, nodeError("nonce too low")},
+		{"out of attempts", []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}, 3, context.DeadlineExceeded},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &sendBackend{errs: test.errs}
+			r := NewRetryBackend(backend, RetryOptions{Policy: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond}})
+			err := r.SendTransaction(context.Background(), types.NewTx(&types.LegacyTx{}))
+			if err != test.err {
+				t.Errorf("error is %v, want %v", err, test.err)
+			}
+			if backend.calls != test.calls {
+				t.Errorf("%d attempts, want %d", backend.calls, test.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package connection
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"golang.org/x/term"
+)
+
+// Signer signs transactions for a single account. Sessions only ever hand
+// transactions to a Signer, so the key material can live in a keystore file,
+// be derived from a mnemonic, or stay behind a remote signer such as Clef.
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// NewSigner builds the signer selected in the configuration:
+//
+//	key      DEPLOYER_PRIVATE_KEY (default, kept for local Ganache setups)
+//	keystore a keystore file, passphrase from a file or a terminal prompt
+//	mnemonic a mnemonic file, optional passphrase file and derivation path
+//	remote   a Clef-style signer URL, optional account
+func NewSigner(ctx context.Context, cfg config.Signer) (Signer, error) {
+	switch cfg.Type {
+	case "", config.SignerKey:
+		if cfg.PrivateKey == "" {
+			return nil, errors.New("DEPLOYER_PRIVATE_KEY is not set")
+		}
+		privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEPLOYER_PRIVATE_KEY: %v", err)
+		}
+		return NewKeySigner(privateKey), nil
+
+	case config.SignerKeystore:
+		passphrase, err := ReadPassphrase(cfg.KeystorePasswordFile, "Keystore passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		return NewKeystoreSigner(cfg.KeystoreFile, passphrase)
+
+	case config.SignerMnemonic:
+		if cfg.MnemonicFile == "" {
+			return nil, errors.New("no mnemonic file configured")
+		}
+		mnemonic, err := os.ReadFile(cfg.MnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic: %v", err)
+		}
+		var passphrase string
+		if cfg.MnemonicPasswordFile != "" {
+			if passphrase, err = ReadPassphrase(cfg.MnemonicPasswordFile, ""); err != nil {
+				return nil, err
+			}
+		}
+		return NewMnemonicSigner(string(mnemonic), passphrase, cfg.HDPath)
+
+	case config.SignerRemote:
+		var account common.Address
+		if cfg.Account != "" {
+			if !common.IsHexAddress(cfg.Account) {
+				return nil, fmt.Errorf("invalid signer account %q", cfg.Account)
+			}
+			account = common.HexToAddress(cfg.Account)
+		}
+		return NewRemoteSigner(ctx, cfg.URL, account)
+
+	default:
+		return nil, fmt.Errorf("unknown signer type %q", cfg.Type)
+	}
+}
+
+// ReadPassphrase reads a passphrase from file, or prompts for it on the
+// terminal without echo when file is empty.
+func ReadPassphrase(file, prompt string) (string, error) {
+	if file != "" {
+		passphrase, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %v", err)
+		}
+		return strings.TrimRight(string(passphrase), "\r\n"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("no passphrase file given and stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %v", err)
+	}
+	return string(passphrase), nil
+}
+
+// KeySigner signs with an in-memory private key.
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (k *KeySigner) Address() common.Address {
+	return k.address
+}
+
+func (k *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), k.key)
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore JSON file.
+func NewKeystoreSigner(path, passphrase string) (*KeySigner, error) {
+	if path == "" {
+		return nil, errors.New("no keystore file configured")
+	}
+
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %v", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %v", path, err)
+	}
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+// NewMnemonicSigner derives the key at hdPath (BIP-32) from a BIP-39 mnemonic.
+// An empty hdPath means m/44'/60'/0'/0/0, the first account most wallets show.
+func NewMnemonicSigner(mnemonic, passphrase, hdPath string) (*KeySigner, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+
+	path := accounts.DefaultBaseDerivationPath
+	if hdPath != "" {
+		var err error
+		if path, err = accounts.ParseDerivationPath(hdPath); err != nil {
+			return nil, fmt.Errorf("invalid derivation path: %v", err)
+		}
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %v", err)
+	}
+
+	key, err := deriveKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key), nil
+}
+
+// deriveKey walks a BIP-32 derivation path from the master key of seed.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive master key: %v", err)
+	}
+	for _, index := range path {
+		if key, err = key.Derive(index); err != nil {
+			return nil, fmt.Errorf("failed to derive child key at index %d: %v", index, err)
+		}
+	}
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	return priv.ToECDSA(), nil
+}
+
+// RemoteSigner asks a Clef-compatible signer over JSON-RPC to sign each
+// transaction; the key never leaves the signer process.
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// NewRemoteSigner connects to the signer at url. When account is the zero
+// address the first account the signer lists is used.
+func NewRemoteSigner(ctx context.Context, url string, account common.Address) (*RemoteSigner, error) {
+	if url == "" {
+		return nil, errors.New("no remote signer URL configured")
+	}
+
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to signer %s: %v", url, err)
+	}
+
+	var listed []common.Address
+	if err := client.CallContext(ctx, &listed, "account_list"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to list signer accounts: %v", err)
+	}
+
+	if account == (common.Address{}) {
+		if len(listed) == 0 {
+			client.Close()
+			return nil, errors.New("remote signer has no accounts")
+		}
+		account = listed[0]
+	} else {
+		found := false
+		for _, addr := range listed {
+			if addr == account {
+				found = true
+				break
+			}
+		}
+		if !found {
+			client.Close()
+			return nil, fmt.Errorf("remote signer does not manage %s", account.Hex())
+		}
+	}
+
+	return &RemoteSigner{client: client, address: account}, nil
+}
+
+func (r *RemoteSigner) Address() common.Address {
+	return r.address
+}
+
+func (r *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		t := common.NewMixedcaseAddress(*tx.To())
+		to = &t
+	}
+	args := &apitypes.SendTxArgs{
+		Data:    &data,
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Value:   hexutil.Big(*tx.Value()),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		To:      to,
+		From:    common.NewMixedcaseAddress(r.address),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
+	}
+
+	var res struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+	if err := r.client.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer refused transaction: %v", err)
+	}
+	// the signer must have signed what was asked, for the account asked
+	if res.Tx == nil {
+		return nil, errors.New("remote signer returned no transaction")
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	if signer.Hash(res.Tx) != signer.Hash(tx) {
+		return nil, errors.New("remote signer returned a different transaction than the one requested")
+	}
+	sender, err := types.Sender(signer, res.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %v", err)
+	}
+	if sender != r.address {
+		return nil, fmt.Errorf("remote signer signed as %s, not %s", sender.Hex(), r.address.Hex())
+	}
+	return res.Tx, nil
+}
+
+// Close disconnects from the signer.
+func (r *RemoteSigner) Close() {
+	r.client.Close()
+}
@@ -0,0 +1,151 @@
+package connection
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Test vector 1 of BIP-32.
+func TestDeriveKeyBIP32(t *testing.T) {
+	seed := common.FromHex("000102030405060708090a0b0c0d0e0f")
+	for _, test := range []struct {
+		path, key string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			var path accounts.DerivationPath
+			if test.path != "m" {
+				var err error
+				if path, err = accounts.ParseDerivationPath(test.path); err != nil {
+					t.Fatal(err)
+				}
+			}
+			key, err := deriveKey(seed, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hexutil.Encode(crypto.FromECDSA(key)); got != "0x"+test.key {
+				t.Errorf("key is %s, want 0x%s", got, test.key)
+			}
+		})
+	}
+}
+
+// The accounts of the well-known development mnemonic, at BIP-44 paths.
+func TestNewMnemonicSigner(t *testing.T) {
+	mnemonic := "test test test test test test test test test test test junk"
+	for _, test := range []struct {
+		path    string
+		address common.Address
+	}{
+		{"", common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")},
+		{"m/44'/60'/0'/0/1", common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")},
+	} {
+		signer, err := NewMnemonicSigner(mnemonic, "", test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer.Address() != test.address {
+			t.Errorf("%q: address is %s, want %s", test.path, signer.Address().Hex(), test.address.Hex())
+		}
+	}
+
+	if _, err := NewMnemonicSigner("test test test", "", ""); err == nil {
+		t.Error("an invalid mnemonic was accepted")
+	}
+}
+
+// accountAPI is a remote signer that signs with key, after letting tamper
+// change the transaction.
+type accountAPI struct {
+	key    *KeySigner
+	tamper func(*types.DynamicFeeTx)
+}
+
+func (a *accountAPI) List() []common.Address {
+	return []common.Address{a.key.Address()}
+}
+
+func (a *accountAPI) SignTransaction(ctx context.Context, args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	inner := &types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Gas:       uint64(args.Gas),
+		Value:     args.Value.ToInt(),
+		Data:      *args.Data,
+	}
+	if args.To != nil {
+		to := args.To.Address()
+		inner.To = &to
+	}
+	if a.tamper != nil {
+		a.tamper(inner)
+	}
+	tx, err := a.key.SignTx(ctx, types.NewTx(inner), inner.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+func TestRemoteSignerChecksSignedTransaction(t *testing.T) {
+	key, _ := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	other, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to, Value: big.NewInt(5)})
+
+	for _, test := range []struct {
+		name   string
+		api    *accountAPI
+		errMsg string
+	}{
+		{"honest", &accountAPI{key: NewKeySigner(key)}, ""},
+		{"changed value", &accountAPI{key: NewKeySigner(key), tamper: func(tx *types.DynamicFeeTx) { tx.Value = big.NewInt(6) }}, "different transaction"},
+		{"changed recipient", &accountAPI{key: NewKeySigner(key), tamper: func(tx *types.DynamicFeeTx) { tx.To = &common.Address{} }}, "different transaction"},
+		{"other account", &accountAPI{key: NewKeySigner(other)}, "signed as"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			defer server.Stop()
+			if err := server.RegisterName("account", test.api); err != nil {
+				t.Fatal(err)
+			}
+			signer := &RemoteSigner{client: rpc.DialInProc(server), address: crypto.PubkeyToAddress(key.PublicKey)}
+			defer signer.Close()
+
+			signed, err := signer.SignTx(context.Background(), tx, chainID)
+			if test.errMsg == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if signed.Hash() == tx.Hash() {
+					t.Error("the transaction came back unsigned")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.errMsg) {
+				t.Errorf("error is %v, want one containing %q", err, test.errMsg)
+			}
+		})
+	}
+}
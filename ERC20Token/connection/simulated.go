@@ -0,0 +1,118 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// simulatedGasLimit is the block gas limit of the simulated chain, enough for
+// the deployment's fixed 30M gas limit.
+const simulatedGasLimit = 30_000_000
+
+// simulatedFunds is what the session account starts with on the simulated
+// chain: a million ether.
+var simulatedFunds = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+
+// errNoFeeHistory makes the fee oracle fall back to SuggestGasTipCap.
+var errNoFeeHistory = errors.New("eth_feeHistory is not supported by the simulated backend")
+
+// SimulatedBackend is an in-process chain built on go-ethereum's
+// backends.SimulatedBackend. It mines a block for every transaction as soon
+// as it is sent, like Ganache does, so code written against a node runs on it
+// unchanged. A transaction sent ahead of its nonce waits, as in a node's
+// transaction pool, until the ones before it are sent.
+type SimulatedBackend struct {
+	*backends.SimulatedBackend
+
+	mu     sync.Mutex
+	queued map[common.Address]map[uint64]*types.Transaction
+}
+
+// NewSimulatedBackend starts a chain whose genesis block holds alloc.
+func NewSimulatedBackend(alloc core.GenesisAlloc) *SimulatedBackend {
+	return &SimulatedBackend{
+		SimulatedBackend: backends.NewSimulatedBackend(alloc, simulatedGasLimit),
+		queued:           make(map[common.Address]map[uint64]*types.Transaction),
+	}
+}
+
+// NewSimulatedSession starts a simulated chain with the signer's account
+// funded and opens a session on it. signer may be nil for a read-only session.
+func NewSimulatedSession(ctx context.Context, opts Options, signer Signer) (*Session, error) {
+	alloc := core.GenesisAlloc{}
+	if signer != nil {
+		alloc[signer.Address()] = core.GenesisAccount{Balance: simulatedFunds}
+	}
+	backend := NewSimulatedBackend(alloc)
+
+	chainID, _ := backend.ChainID(ctx)
+	profile := ChainProfile{ChainID: chainID.Uint64(), Name: "the simulated backend", Confirmations: 1}
+
+	session, err := open(ctx, backend, chainID, profile, opts, signer)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+func (b *SimulatedBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.Blockchain().Config().ChainID), nil
+}
+
+func (b *SimulatedBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.Blockchain().CurrentBlock().Number.Uint64(), nil
+}
+
+func (b *SimulatedBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return nil, errNoFeeHistory
+}
+
+// SendTransaction sends tx and mines it into a block of its own, then those
+// it was holding up. A transaction ahead of its nonce is queued instead.
+func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	nonce, err := b.SimulatedBackend.PendingNonceAt(ctx, from)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() > nonce {
+		if b.queued[from] == nil {
+			b.queued[from] = make(map[uint64]*types.Transaction)
+		}
+		b.queued[from][tx.Nonce()] = tx
+		return nil
+	}
+
+	if err := b.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.Commit()
+
+	// a queued transaction that fails now is dropped, as a node drops it
+	for next := tx.Nonce() + 1; b.queued[from][next] != nil; next++ {
+		queued := b.queued[from][next]
+		delete(b.queued[from], next)
+		if b.SimulatedBackend.SendTransaction(ctx, queued) != nil {
+			break
+		}
+		b.Commit()
+	}
+	return nil
+}
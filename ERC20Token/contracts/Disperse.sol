@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.19;
+import "node_modules/@openzeppelin/contracts/token/ERC20/IERC20.sol";
+import "node_modules/@openzeppelin/contracts/token/ERC20/utils/SafeERC20.sol";
+
+// Disperse sends tokens to many recipients in one transaction. The caller
+// approves it for the total first; every recipient then gets its amount
+// straight from the caller, so Transfer logs show the caller as sender.
+contract Disperse {
+    using SafeERC20 for IERC20;
+
+    function disperseToken(IERC20 token, address[] calldata recipients, uint256[] calldata values) external {
+        require(recipients.length == values.length, "Disperse: length mismatch");
+        for (uint256 i = 0; i < recipients.length; i++) {
+            token.safeTransferFrom(msg.sender, recipients[i], values[i]);
+        }
+    }
+}
@@ -2,8 +2,23 @@
 pragma solidity ^0.8.19;
 import "node_modules/@openzeppelin/contracts/token/ERC20/ERC20.sol";
 
+// TestERC20 is a plain ERC20 token whose name, symbol and decimals are set
+// at deployment, with the whole supply minted to one holder.
 contract TestERC20 is ERC20 {
-    constructor() ERC20("TestERC20", "TST") {
-        _mint(msg.sender, 1000 * 10 ** decimals());
+    uint8 private immutable _decimals;
+
+    constructor(
+        string memory name_,
+        string memory symbol_,
+        uint8 decimals_,
+        uint256 initialSupply,
+        address initialHolder
+    ) ERC20(name_, symbol_) {
+        _decimals = decimals_;
+        _mint(initialHolder, initialSupply);
+    }
+
+    function decimals() public view override returns (uint8) {
+        return _decimals;
     }
-}
\ No newline at end of file
+}
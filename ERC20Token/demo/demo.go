@@ -0,0 +1,141 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/deploy"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/tracker"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/wallet"
+)
+
+// Run deploys TestERC20 on a simulated chain, airdrops tokens to
+// cfg.Generator.Recipients fresh wallets in the given number of rounds of
+// cfg.Generator.TransfersPerTick parallel transfers, and tracks them with the
+// same watcher TokenTracker uses. With batch, each round is one BatchTransfer
+// through a freshly deployed Disperse instead. At the end the tracked totals
+// are checked against the recipients' token balances.
+func Run(ctx context.Context, cfg *config.Config, rounds int, batch bool) error {
+	simulated := *cfg
+	simulated.RPC.Backend = config.BackendSimulated
+
+	session, err := connection.NewSession(ctx, &simulated)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	deployment, err := deploy.DeployTestERC20(ctx, session, deploy.DefaultTokenParams())
+	if err != nil {
+		return err
+	}
+	token := deployment.Address
+
+	unit, err := interact.TokenUnit(ctx, session.Client, token.Hex())
+	if err != nil {
+		return err
+	}
+
+	head, err := session.Client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	sums := tracker.NewSums()
+	sums.SetUnit(unit)
+	watcher, err := tracker.NewWatcher(session.Client, token, head+1, sums, cfg.Tracker.ResubscribeInterval)
+	if err != nil {
+		return err
+	}
+
+	// the simulated chain is thrown away, so are the recipients' keys
+	dir, err := os.MkdirTemp("", "demo-wallets-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	store, err := wallet.Open(dir, "demo", true)
+	if err != nil {
+		return err
+	}
+	wallets, err := store.Ensure("recipient", cfg.Generator.Recipients)
+	if err != nil {
+		return err
+	}
+	recipients := make([]common.Address, len(wallets))
+	for i, w := range wallets {
+		recipients[i] = w.Address
+	}
+
+	var disperse common.Address
+	if batch {
+		deployment, err := deploy.DeployDisperse(ctx, session)
+		if err != nil {
+			return err
+		}
+		disperse = deployment.Address
+	}
+
+	for round := 1; round <= rounds; round++ {
+		if batch {
+			to := make([]common.Address, cfg.Generator.TransfersPerTick)
+			amounts := make([]units.Amount, len(to))
+			for i := range to {
+				to[i] = recipients[rand.Intn(len(recipients))]
+				amounts[i] = unit.Tokens(int64(rand.Intn(100) + 1))
+			}
+			if _, err := interact.BatchTransfer(ctx, session, token.Hex(), disperse.Hex(), to, amounts, interact.BatchOptions{}); err != nil {
+				return err
+			}
+		} else {
+			var wg sync.WaitGroup
+			errs := make([]error, cfg.Generator.TransfersPerTick)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					recipient := recipients[rand.Intn(len(recipients))]
+					_, errs[i] = interact.TransferTokens(ctx, session, token.Hex(), recipient, unit.Tokens(int64(rand.Intn(100)+1)))
+				}(i)
+			}
+			wg.Wait()
+			for _, err := range errs {
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := watcher.Poll(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("------------------------------------------------------------------------\nRound %d of %d\n", round, rounds)
+		sums.Print()
+		sums.ResetInterval()
+	}
+
+	testERC20, err := contractsgo.NewTestERC20(token, session.Client)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		balance, err := testERC20.BalanceOf(&bind.CallOpts{Context: ctx}, recipient)
+		if err != nil {
+			return err
+		}
+		if tracked := sums.Total(recipient); tracked.Cmp(balance) != 0 {
+			return fmt.Errorf("tracked %s for %s, but its balance is %s", unit.Format(tracked), recipient.Hex(), unit.Format(balance))
+		}
+	}
+	fmt.Println("Tracked totals match the token balances of all recipients")
+	return nil
+}
@@ -0,0 +1,29 @@
+package demo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
+
+// Run fails unless the totals the watcher tracked match the recipients'
+// balances, so a clean run covers deploy, transfer and tracking end to end.
+func TestRun(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		batch bool
+	}{
+		{"transfers", false},
+		{"batch", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Generator.Recipients = 3
+			cfg.Generator.TransfersPerTick = 4
+			if err := Run(context.Background(), cfg, 2, test.batch); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
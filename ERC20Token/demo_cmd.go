@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/demo"
+)
+
+// runDemo runs deploy, airdrop and tracking in-process for -rounds rounds,
+// each sent as one batch with -batch.
+func runDemo(args []string) error {
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	rounds := fs.Int("rounds", 3, "airdrop rounds to run")
+	batch := fs.Bool("batch", false, "send each round as one batch through a Disperse contract")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	return demo.Run(context.Background(), cfg, *rounds, *batch)
+}
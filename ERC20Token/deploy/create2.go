@@ -0,0 +1,207 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+)
+
+// FactoryAddress is the deterministic deployment proxy: a CREATE2 factory
+// installed by the same presigned transaction on every chain, so it is at
+// this address everywhere. Its calldata is a 32-byte salt followed by the
+// init code, and it returns the address it created.
+var FactoryAddress = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// factoryTx installs the factory. It is signed without a chain ID by a key
+// nobody holds, so it can only ever run as nonce 0 of its sender.
+var factoryTx = common.FromHex("0xf8a58085174876e800830186a08080b853604580600e600039806000f350fe7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf31ba02222222222222222222222222222222222222222222222222222222222222222a02222222222222222222222222222222222222222222222222222222222222222")
+
+// Create2Address returns where the factory deploys initCode with salt.
+func Create2Address(salt common.Hash, initCode []byte) common.Address {
+	return crypto.CreateAddress2(FactoryAddress, salt, crypto.Keccak256(initCode))
+}
+
+// EnsureFactory installs the factory on the session's chain if it isn't
+// there yet, funding the account that sends the presigned transaction from
+// the session account.
+func EnsureFactory(ctx context.Context, session *connection.Session) error {
+	code, err := session.Client.CodeAt(ctx, FactoryAddress, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get code at %s: %v", FactoryAddress.Hex(), err)
+	}
+	if len(code) > 0 {
+		return nil
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(factoryTx); err != nil {
+		return fmt.Errorf("failed to decode the factory transaction: %v", err)
+	}
+	sender, err := types.Sender(types.HomesteadSigner{}, tx)
+	if err != nil {
+		return fmt.Errorf("failed to recover the factory deployer: %v", err)
+	}
+	nonce, err := session.Client.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce of %s: %v", sender.Hex(), err)
+	}
+	if nonce > 0 {
+		return fmt.Errorf("the factory deployer %s has already sent transactions, yet there is no factory at %s", sender.Hex(), FactoryAddress.Hex())
+	}
+
+	fmt.Printf("Installing the CREATE2 factory at %s...\n", FactoryAddress.Hex())
+	balance, err := session.Client.BalanceAt(ctx, sender, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get balance of %s: %v", sender.Hex(), err)
+	}
+	if shortfall := new(big.Int).Sub(tx.Cost(), balance); shortfall.Sign() > 0 {
+		auth, err := session.NextTransaction(ctx)
+		if err != nil {
+			return err
+		}
+		// a plain transfer; binding's estimate refuses an address without code
+		auth.Value, auth.GasLimit = shortfall, 21000
+		funding, err := bind.NewBoundContract(sender, abi.ABI{}, session.Client, session.Client, session.Client).Transfer(auth)
+		if err != nil {
+			session.TransactionFailed(auth, err)
+			return fmt.Errorf("failed to fund the factory deployer %s: %w", sender.Hex(), reverts.Wrap(err))
+		}
+		if _, err := bind.WaitMined(ctx, session.Client, funding); err != nil {
+			return fmt.Errorf("failed waiting for funding transaction %s: %v", funding.Hash().Hex(), err)
+		}
+	}
+
+	if err := session.Client.SendTransaction(ctx, tx); err != nil {
+		return fmt.Errorf("failed to send the factory transaction, which has no chain ID; the node must accept such transactions (geth needs --rpc.allow-unprotected-txs): %v", err)
+	}
+	receipt, err := bind.WaitMined(ctx, session.Client, tx)
+	if err != nil {
+		return fmt.Errorf("failed waiting for factory transaction %s: %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("factory transaction %s failed", tx.Hash().Hex())
+	}
+	fmt.Println("The CREATE2 factory is installed")
+	return nil
+}
+
+// DeployTestERC20Create2 deploys TestERC20 with params through the factory
+// with salt, so that the same params and salt give the same address on every
+// chain, whoever deploys it; params.Holder is required for that, as it is
+// part of the init code. If that address already has code, nothing is sent
+// and the deployment found there is returned, with no transaction or
+// deployer.
+func DeployTestERC20Create2(ctx context.Context, session *connection.Session, params TokenParams, salt common.Hash) (registry.Deployment, error) {
+	if err := params.validate(); err != nil {
+		return registry.Deployment{}, err
+	}
+	if params.Holder == (common.Address{}) {
+		return registry.Deployment{}, errors.New("token holder is required for a CREATE2 deployment")
+	}
+	args := []interface{}{params.Name, params.Symbol, params.Decimals, params.Supply, params.Holder}
+
+	parsed, err := contractsgo.TestERC20MetaData.GetAbi()
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	encoded, err := parsed.Pack("", args...)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	_, bin, err := artifact("TestERC20")
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	initCode := append(bin, encoded...)
+	address := Create2Address(salt, initCode)
+
+	fmt.Printf("TestERC20 contract with CREATE2: %s (%s), %d decimals, %s to %s, salt %s\n", params.Name, params.Symbol, params.Decimals, params.Unit().Format(params.Supply), params.Holder.Hex(), salt.Hex())
+	fmt.Println("Its address is", address.Hex())
+
+	code, err := session.Client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return registry.Deployment{}, fmt.Errorf("failed to get code at %s: %v", address.Hex(), err)
+	}
+	// who deployed a contract found at the address isn't known
+	var deployer common.Address
+	var receipt *types.Receipt
+	if len(code) > 0 {
+		fmt.Println("It is already deployed; skipping the deployment")
+		fmt.Println()
+	} else {
+		if err := EnsureFactory(ctx, session); err != nil {
+			return registry.Deployment{}, err
+		}
+		if receipt, err = create2(ctx, session, address, salt, initCode); err != nil {
+			return registry.Deployment{}, err
+		}
+		deployer = session.From
+	}
+
+	deployment, err := newDeployment("TestERC20", contractsgo.TestERC20MetaData, deployer, address, receipt, args...)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	factory := FactoryAddress
+	deployment.Factory, deployment.Salt = &factory, &salt
+	return verifyDeployed(ctx, session, deployment)
+}
+
+// create2 sends initCode with salt to the factory and waits until the
+// contract at address is mined and confirmed. Its gas is estimated with a
+// tenth on top, as the factory's CREATE2 gets only 63/64 of what is left.
+func create2(ctx context.Context, session *connection.Session, address common.Address, salt common.Hash, initCode []byte) (*types.Receipt, error) {
+	data := append(salt.Bytes(), initCode...)
+	factoryAddress := FactoryAddress
+	gas, err := session.Client.EstimateGas(ctx, ethereum.CallMsg{From: session.From, To: &factoryAddress, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas of the deployment through the factory: %w", reverts.Wrap(err))
+	}
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auth.GasLimit = gas + gas/10
+
+	factory := bind.NewBoundContract(FactoryAddress, abi.ABI{}, session.Client, session.Client, session.Client)
+	tx, err := factory.RawTransact(auth, data)
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return nil, fmt.Errorf("failed to deploy through the factory: %w", reverts.Wrap(err))
+	}
+
+	receipt, err := bind.WaitMined(ctx, session.Client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for deployment of %s: %v", tx.Hash().Hex(), err)
+	}
+	code, err := session.Client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code at %s: %v", address.Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || len(code) == 0 {
+		return nil, fmt.Errorf("deployment %s through the factory created no contract at %s", tx.Hash().Hex(), address.Hex())
+	}
+	return confirmDeployed(ctx, session, address, tx)
+}
+
+// create2Input returns the salt and init code of a call to the factory, or
+// false if data is too short to be one.
+func create2Input(data []byte) (common.Hash, []byte, bool) {
+	if len(data) <= common.HashLength {
+		return common.Hash{}, nil, false
+	}
+	return common.BytesToHash(data[:common.HashLength]), data[common.HashLength:], true
+}
@@ -0,0 +1,79 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+)
+
+func TestCreate2Address(t *testing.T) {
+	for _, test := range []struct {
+		salt     common.Hash
+		initCode string
+		want     common.Address
+	}{
+		{common.Hash{}, "0x00", common.HexToAddress("0x24c4fd2db1cf4cb1aec651cc0e060a00d400e784")},
+		{common.BigToHash(common.Big1), "0x00", common.HexToAddress("0x310482BEd3c133C904cd2708A0F6e24062431E0c")},
+		{common.HexToHash("0x2a"), "0x6080604052", common.HexToAddress("0xf0016b2c98ef82bcb94ded05397855e907c2ccfe")},
+	} {
+		if got := Create2Address(test.salt, common.FromHex(test.initCode)); got != test.want {
+			t.Errorf("salt %s, init code %s: address is %s, want %s", test.salt.Hex(), test.initCode, got.Hex(), test.want.Hex())
+		}
+	}
+}
+
+// The first deployment installs the factory and deploys with estimated gas;
+// the second finds the token at its address and sends nothing.
+func TestDeployTestERC20Create2(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connection.NewSimulatedSession(ctx, connection.Options{}, connection.NewKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	params := DefaultTokenParams()
+	params.Holder = session.From
+	salt := common.HexToHash("0x01")
+
+	first, err := DeployTestERC20Create2(ctx, session, params, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Deployer != session.From || first.TxHash == (common.Hash{}) {
+		t.Fatalf("first deployment is by %s in %s, want one by the session account", first.Deployer.Hex(), first.TxHash.Hex())
+	}
+	tx, _, err := session.Client.TransactionByHash(ctx, first.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := session.Client.TransactionReceipt(ctx, first.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Gas() < receipt.GasUsed || tx.Gas() > receipt.GasUsed*3/2 {
+		t.Errorf("gas limit is %d for %d used, want a limit fitted to the deployment", tx.Gas(), receipt.GasUsed)
+	}
+
+	nonce, err := session.Client.PendingNonceAt(ctx, session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := DeployTestERC20Create2(ctx, session, params, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Address != first.Address || second.Deployer != (common.Address{}) || second.TxHash != (common.Hash{}) {
+		t.Fatalf("second deployment is %+v, want the first's address with no deployer or transaction", second)
+	}
+	if after, err := session.Client.PendingNonceAt(ctx, session.From); err != nil || after != nonce {
+		t.Fatalf("nonce went from %d to %d (%v), want nothing sent", nonce, after, err)
+	}
+}
@@ -2,59 +2,287 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
-	"os"
-	"path/filepath"
+	"time"
 
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
-func deployTestERC20Contract() string {
-	auth, client, fromAddress, nonce, gasPrice, _ := connection.GetNextTransaction()
+// TokenParams are the constructor arguments of TestERC20.
+type TokenParams struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+	// Supply is minted to Holder at deployment, in base units.
+	Supply *big.Int
+	// Holder receives the supply; the deploying account if zero.
+	Holder common.Address
+}
+
+// DefaultTokenParams returns the token TestERC20 always was before it took
+// parameters: 1000 TST of 18 decimals, held by the deployer.
+func DefaultTokenParams() TokenParams {
+	unit := units.Unit{Symbol: "TST", Decimals: 18}
+	return TokenParams{Name: "TestERC20", Symbol: unit.Symbol, Decimals: unit.Decimals, Supply: unit.Tokens(1000).Value}
+}
 
-	fmt.Println("Deploying TestERC20 contract...")
+// Unit returns the unit the token's amounts are in.
+func (p TokenParams) Unit() units.Unit {
+	return units.Unit{Symbol: p.Symbol, Decimals: p.Decimals}
+}
+
+func (p TokenParams) validate() error {
+	if p.Name == "" {
+		return errors.New("token name is required")
+	}
+	if p.Symbol == "" {
+		return errors.New("token symbol is required")
+	}
+	if p.Supply == nil || p.Supply.Sign() < 0 {
+		return errors.New("token supply must not be negative")
+	}
+	return nil
+}
+
+// deployTestERC20Contract deploys TestERC20 with params, through the CREATE2
+// factory if salt is set, and records it as name, replacing another
+// deployment of that name only if replace is set.
+func deployTestERC20Contract(ctx context.Context, session *connection.Session, deploymentsFile, name string, params TokenParams, salt *common.Hash, replace bool) (registry.Deployment, error) {
+	var deployment registry.Deployment
+	var err error
+	if salt != nil {
+		deployment, err = DeployTestERC20Create2(ctx, session, params, *salt)
+	} else {
+		deployment, err = DeployTestERC20(ctx, session, params)
+	}
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	if err := Record(deploymentsFile, session.ChainID, name, deployment, replace); err != nil {
+		return registry.Deployment{}, err
+	}
+	return deployment, nil
+}
+
+// Record adds a deployment to the registry at deploymentsFile as name. A
+// name recorded for another address is only replaced if replace is set.
+func Record(deploymentsFile string, chainID *big.Int, name string, deployment registry.Deployment, replace bool) error {
+	replaced, err := registry.Record(deploymentsFile, chainID, name, deployment, replace)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deployment recorded as %q in %s\n", name, deploymentsFile)
+	if replaced != nil && replaced.Address != deployment.Address {
+		fmt.Printf("It replaces the %s deployed at %s\n", replaced.Contract, replaced.Address.Hex())
+	}
+	return nil
+}
+
+// DeployTestERC20 deploys TestERC20 with params from the session account and
+// waits until it is mined and confirmed.
+func DeployTestERC20(ctx context.Context, session *connection.Session, params TokenParams) (registry.Deployment, error) {
+	if err := params.validate(); err != nil {
+		return registry.Deployment{}, err
+	}
+	if params.Holder == (common.Address{}) {
+		params.Holder = session.From
+	}
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+
+	fmt.Printf("Deploying TestERC20 contract: %s (%s), %d decimals, %s to %s...\n", params.Name, params.Symbol, params.Decimals, params.Unit().Format(params.Supply), params.Holder.Hex())
 
-	auth.From = fromAddress
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)
 	auth.GasLimit = uint64(30000000)
-	auth.GasPrice = gasPrice
 
-	address, tx, _, err := contractsgo.DeployTestERC20(auth, client)
+	address, tx, _, err := contractsgo.DeployTestERC20(auth, session.Client, params.Name, params.Symbol, params.Decimals, params.Supply, params.Holder)
 	if err != nil {
-		fmt.Println("here")
-		log.Fatal(err)
+		session.TransactionFailed(auth, err)
+		return registry.Deployment{}, fmt.Errorf("failed to deploy TestERC20: %w", reverts.Wrap(err))
 	}
 
-	_, err = bind.WaitDeployed(context.Background(), client, tx)
+	receipt, err := waitDeployed(ctx, session, address, tx)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	deployment, err := newDeployment("TestERC20", contractsgo.TestERC20MetaData, session.From, address, receipt, params.Name, params.Symbol, params.Decimals, params.Supply, params.Holder)
 	if err != nil {
-		fmt.Println("there")
-		log.Fatal(err)
+		return registry.Deployment{}, err
 	}
+	return verifyDeployed(ctx, session, deployment)
+}
 
-	fmt.Println("The contract is deployed at address: ", address)
-	fmt.Printf("Transaction hash: 0x%x\n\n", tx.Hash())
+// DeployDisperse deploys the Disperse batch-transfer contract from the
+// session account and waits until it is mined and confirmed.
+func DeployDisperse(ctx context.Context, session *connection.Session) (registry.Deployment, error) {
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+
+	fmt.Println("Deploying Disperse contract...")
+
+	address, tx, _, err := contractsgo.DeployDisperse(auth, session.Client)
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return registry.Deployment{}, fmt.Errorf("failed to deploy Disperse: %w", reverts.Wrap(err))
+	}
+
+	receipt, err := waitDeployed(ctx, session, address, tx)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	deployment, err := newDeployment("Disperse", contractsgo.DisperseMetaData, session.From, address, receipt)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	return verifyDeployed(ctx, session, deployment)
+}
 
-	rootDir := filepath.Join("./")
-	filePath := filepath.Join(rootDir, "contract_address.txt")
-	err = os.WriteFile(filePath, []byte(address.String()), 0644)
+// newDeployment describes a deployment of the build artifacts of contract,
+// whose binding has meta, with the constructor arguments args. receipt is
+// nil if the transaction that deployed it is unknown.
+func newDeployment(contract string, meta *bind.MetaData, deployer, address common.Address, receipt *types.Receipt, args ...interface{}) (registry.Deployment, error) {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	abiFile, bin, err := artifact(contract)
 	if err != nil {
-		log.Fatalf("Failed to write contract address: %v", err)
+		return registry.Deployment{}, err
 	}
 
-	fmt.Printf("Contract address saved to: %s\n", filePath)
+	encoded, err := parsed.Constructor.Inputs.Pack(args...)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+
+	d := registry.Deployment{
+		Contract:     contract,
+		Address:      address,
+		Deployer:     deployer,
+		EncodedArgs:  encoded,
+		ABIHash:      crypto.Keccak256Hash(abiFile),
+		BytecodeHash: crypto.Keccak256Hash(bin),
+		Time:         time.Now().UTC(),
+	}
+	if receipt != nil {
+		d.TxHash, d.Block = receipt.TxHash, receipt.BlockNumber.Uint64()
+	}
+	for i, input := range parsed.Constructor.Inputs {
+		d.Args = append(d.Args, registry.Arg{Name: input.Name, Type: input.Type.String(), Value: fmt.Sprint(args[i])})
+	}
+	return d, nil
+}
 
-	return address.String()
+// waitDeployed waits for a deployment to be mined and confirmed, prints
+// where it went and returns its receipt.
+func waitDeployed(ctx context.Context, session *connection.Session, address common.Address, tx *types.Transaction) (*types.Receipt, error) {
+	_, err := bind.WaitDeployed(ctx, session.Client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for deployment of %s: %v", tx.Hash().Hex(), err)
+	}
+	return confirmDeployed(ctx, session, address, tx)
+}
+
+// confirmDeployed prints where the mined deployment tx went, waits for its
+// confirmations and returns its receipt.
+func confirmDeployed(ctx context.Context, session *connection.Session, address common.Address, tx *types.Transaction) (*types.Receipt, error) {
+	fmt.Println("The contract is deployed at address: ", address)
+	fmt.Printf("Transaction hash: 0x%x\n", tx.Hash())
+	if url := session.Profile.TxURL(tx.Hash()); url != "" {
+		fmt.Println("Explorer:", url)
+	}
+	fmt.Println()
+
+	receipt, err := session.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %v", tx.Hash().Hex(), err)
+	}
+	if err := session.WaitConfirmations(ctx, receipt.BlockNumber); err != nil {
+		return nil, fmt.Errorf("failed waiting for confirmations of %s: %v", tx.Hash().Hex(), err)
+	}
+	return receipt, nil
 }
 
-func RunTestERC20Contract() {
-	testERC20ContractAddress := deployTestERC20Contract()
-	toAddress := connection.GenerateNewWallet()
-	interact.TransferTokens(testERC20ContractAddress, toAddress, 10)
+// RunTestERC20Contract deploys TestERC20 with params, records it in the
+// deployment registry as name and sends a test transfer of up to 10 tokens
+// to testTo, if it is set and the deployer holds the supply. With a salt it
+// deploys through the CREATE2 factory, and a token already at that address
+// is only recorded. A name already recorded is refused before anything is
+// deployed unless replace is set; under CREATE2 the address is known only
+// once computed, so the same address may be recorded again.
+func RunTestERC20Contract(ctx context.Context, cfg *config.Config, name string, params TokenParams, salt *common.Hash, testTo common.Address, replace bool) error {
+	if err := registry.CheckName(name); err != nil {
+		return err
+	}
+
+	session, err := connection.NewSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// refuse production chains before anything is deployed
+	if err := session.CheckWritable(); err != nil {
+		return err
+	}
+
+	if !replace && salt == nil {
+		r, err := registry.Load(cfg.Paths.DeploymentsFile)
+		if err != nil {
+			return err
+		}
+		if previous, ok := r.Lookup(session.ChainID, name); ok {
+			return fmt.Errorf("%w: %q on chain %s is the %s at %s; pick another name or replace it", registry.ErrNameTaken, name, session.ChainID, previous.Contract, previous.Address.Hex())
+		}
+	}
+
+	deployment, err := deployTestERC20Contract(ctx, session, cfg.Paths.DeploymentsFile, name, params, salt, replace)
+	if err != nil {
+		return err
+	}
+	testERC20ContractAddress := deployment.Address.Hex()
+
+	if testTo == (common.Address{}) {
+		return nil
+	}
+	if deployment.TxHash == (common.Hash{}) {
+		fmt.Println("Skipping the test transfer: the token was deployed before")
+		return nil
+	}
+
+	if params.Holder != (common.Address{}) && params.Holder != session.From {
+		fmt.Printf("Skipping the test transfer: the supply went to %s\n", params.Holder.Hex())
+		return nil
+	}
+	if params.Supply.Sign() == 0 {
+		fmt.Println("Skipping the test transfer: no tokens were minted")
+		return nil
+	}
+	amount := params.Unit().Tokens(10)
+	if amount.Value.Cmp(params.Supply) > 0 {
+		amount = params.Unit().Amount(params.Supply)
+	}
+
+	result, err := interact.TransferTokens(ctx, session, testERC20ContractAddress, testTo, amount)
+	if result != nil && result.Balances != nil {
+		result.Balances.Print()
+	}
+	return err
 }
@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+)
+
+// A deployment with a test transfer needs no wallet store, and a second one
+// under the same name is refused before it is sent.
+func TestRunTestERC20Contract(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.RPC.Backend = config.BackendSimulated
+	cfg.Paths.DeploymentsFile = filepath.Join(t.TempDir(), "deployments.json")
+	cfg.Wallets.Dir, cfg.Wallets.PasswordFile = filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "missing")
+	to := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	if err := RunTestERC20Contract(ctx, cfg, "token", DefaultTokenParams(), nil, to, false); err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.Load(cfg.Paths.DeploymentsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Lookup(big.NewInt(1337), "token"); !ok {
+		t.Fatal("the deployment isn't recorded as token")
+	}
+
+	if err := RunTestERC20Contract(ctx, cfg, "token", DefaultTokenParams(), nil, to, false); !errors.Is(err, registry.ErrNameTaken) {
+		t.Fatalf("error is %v, want ErrNameTaken", err)
+	}
+	if err := RunTestERC20Contract(ctx, cfg, "token", DefaultTokenParams(), nil, common.Address{}, true); err != nil {
+		t.Fatal(err)
+	}
+}
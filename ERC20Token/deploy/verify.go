@@ -0,0 +1,274 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/build"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+)
+
+// Verification is what Verify found out about a deployment. Problems are
+// the checks that failed; Notes are differences that don't change the code.
+type Verification struct {
+	Deployment registry.Deployment
+	Problems   []string
+	Notes      []string
+}
+
+// OK reports whether every check passed.
+func (v *Verification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+func (v *Verification) problem(format string, args ...interface{}) {
+	v.Problems = append(v.Problems, fmt.Sprintf(format, args...))
+}
+
+// Print shows the outcome, one line per problem or note.
+func (v *Verification) Print() {
+	d := v.Deployment
+	if v.OK() {
+		fmt.Printf("%s at %s matches build/%s.bin\n", d.Contract, d.Address.Hex(), d.Contract)
+	} else {
+		fmt.Printf("%s at %s does NOT match build/%s.bin:\n", d.Contract, d.Address.Hex(), d.Contract)
+	}
+	for _, problem := range v.Problems {
+		fmt.Println("  -", problem)
+	}
+	for _, note := range v.Notes {
+		fmt.Println("  note:", note)
+	}
+}
+
+// verifyDeployed verifies a deployment that was just mined, and fails if it
+// doesn't match the build.
+func verifyDeployed(ctx context.Context, session *connection.Session, d registry.Deployment) (registry.Deployment, error) {
+	v, err := Verify(ctx, session.Client, d)
+	if err != nil {
+		return registry.Deployment{}, err
+	}
+	v.Print()
+	if !v.OK() {
+		return registry.Deployment{}, fmt.Errorf("the deployment at %s failed verification", d.Address.Hex())
+	}
+	return d, nil
+}
+
+// Verify checks a deployment against the build artifacts of its contract:
+//   - the deployment transaction ran the artifact's creation code with the
+//     recorded constructor arguments; for CREATE2, through the factory with
+//     the recorded salt, and that creation code and salt give the address.
+//     A CREATE2 deployment found already deployed has no transaction, and
+//     its recorded arguments are checked against the address instead
+//   - the runtime code at the address is what that creation code deploys,
+//     run with eth_call on the same chain so constructor arguments and
+//     immutables are filled in the same way; code that differs only in the
+//     compiler's metadata trailer is noted, not failed
+//   - a token's name, symbol, decimals and total supply are the ones its
+//     constructor was given
+//
+// The error is only for failures to read the chain.
+func Verify(ctx context.Context, client connection.Backend, d registry.Deployment) (*Verification, error) {
+	v := &Verification{Deployment: d}
+
+	abiFile, bin, err := artifact(d.Contract)
+	if err != nil {
+		v.problem("%v", err)
+		return v, nil
+	}
+	if crypto.Keccak256Hash(abiFile) != d.ABIHash || crypto.Keccak256Hash(bin) != d.BytecodeHash {
+		v.Notes = append(v.Notes, fmt.Sprintf("build/%s was rebuilt since the deployment", d.Contract))
+	}
+	parsed, err := abi.JSON(bytes.NewReader(abiFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI of %s: %v", d.Contract, err)
+	}
+
+	// the creation code and constructor arguments the deployment ran
+	input, ok, err := creationInput(ctx, client, v, bin)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return v, nil
+	}
+	args, err := parsed.Constructor.Inputs.UnpackValues(input[len(bin):])
+	if err != nil {
+		v.problem("the constructor arguments don't decode: %v", err)
+		return v, nil
+	}
+	values := make(map[string]string)
+	for i, input := range parsed.Constructor.Inputs {
+		values[input.Name] = fmt.Sprint(args[i])
+	}
+	for _, arg := range d.Args {
+		if values[arg.Name] != arg.Value {
+			v.problem("constructor argument %s is %s, the registry says %s", arg.Name, values[arg.Name], arg.Value)
+		}
+	}
+
+	// the runtime code those arguments give, against the code on the chain
+	// the factory is the creator of a CREATE2 deployment
+	from := d.Deployer
+	if d.Factory != nil {
+		from = *d.Factory
+	}
+	expected, err := client.CallContract(ctx, ethereum.CallMsg{From: from, Data: append(bin[:len(bin):len(bin)], input[len(bin):]...)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the creation code of %s: %v", d.Contract, err)
+	}
+	code, err := client.CodeAt(ctx, d.Address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code at %s: %v", d.Address.Hex(), err)
+	}
+	switch {
+	case len(code) == 0:
+		v.problem("there is no code at %s", d.Address.Hex())
+	case bytes.Equal(code, expected):
+	case bytes.Equal(stripMetadata(code), stripMetadata(expected)):
+		v.Notes = append(v.Notes, "the runtime code differs from the artifact's only in the metadata hash")
+	default:
+		v.problem("the runtime code differs from the one the artifact deploys")
+	}
+
+	if d.Contract == "TestERC20" && len(code) > 0 {
+		if err := verifyToken(ctx, client, v, values); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// creationInput returns the creation code and constructor arguments the
+// deployment ran, or false after recording the problem if they can't be
+// found or don't match the artifact's bin.
+func creationInput(ctx context.Context, client connection.Backend, v *Verification, bin []byte) ([]byte, bool, error) {
+	d := v.Deployment
+	if d.Factory != nil && d.Salt == nil {
+		v.problem("the deployment through factory %s has no salt", d.Factory.Hex())
+		return nil, false, nil
+	}
+
+	var input []byte
+	switch {
+	case d.TxHash == (common.Hash{}) && d.Factory != nil:
+		input = append(bin[:len(bin):len(bin)], d.EncodedArgs...)
+		v.Notes = append(v.Notes, "the deployment transaction is unknown; the recorded constructor arguments were checked against the address")
+	case d.TxHash == (common.Hash{}):
+		v.problem("no deployment transaction is recorded")
+		return nil, false, nil
+	default:
+		tx, _, err := client.TransactionByHash(ctx, d.TxHash)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get deployment transaction %s: %v", d.TxHash.Hex(), err)
+		}
+		receipt, err := client.TransactionReceipt(ctx, d.TxHash)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get receipt of %s: %v", d.TxHash.Hex(), err)
+		}
+		if d.Factory == nil {
+			if tx.To() != nil || receipt.ContractAddress != d.Address {
+				v.problem("transaction %s did not create %s", d.TxHash.Hex(), d.Address.Hex())
+				return nil, false, nil
+			}
+			input = tx.Data()
+			break
+		}
+		if tx.To() == nil || *tx.To() != *d.Factory || receipt.Status != types.ReceiptStatusSuccessful {
+			v.problem("transaction %s did not deploy through factory %s", d.TxHash.Hex(), d.Factory.Hex())
+			return nil, false, nil
+		}
+		salt, code, ok := create2Input(tx.Data())
+		if !ok || salt != *d.Salt {
+			v.problem("transaction %s did not deploy with salt %s", d.TxHash.Hex(), d.Salt.Hex())
+			return nil, false, nil
+		}
+		input = code
+	}
+	if d.Factory != nil {
+		if address := crypto.CreateAddress2(*d.Factory, *d.Salt, crypto.Keccak256(input)); address != d.Address {
+			v.problem("the creation code with salt %s deploys to %s, not %s", d.Salt.Hex(), address.Hex(), d.Address.Hex())
+			return nil, false, nil
+		}
+	}
+
+	if len(input) < len(bin) {
+		v.problem("the deployment's creation code is shorter than the artifact's")
+		return nil, false, nil
+	}
+	if !bytes.Equal(input[:len(bin)], bin) {
+		if !bytes.Equal(stripMetadata(input[:len(bin)]), stripMetadata(bin)) {
+			v.problem("the deployment's creation code differs from the artifact's")
+			return nil, false, nil
+		}
+		v.Notes = append(v.Notes, "the creation code differs from the artifact's only in the metadata hash")
+	}
+	return input, true, nil
+}
+
+// verifyToken checks the token's metadata and supply against the constructor
+// arguments it was deployed with.
+func verifyToken(ctx context.Context, client connection.Backend, v *Verification, args map[string]string) error {
+	token, err := erc20.Open(ctx, client, v.Deployment.Address)
+	if err != nil {
+		return err
+	}
+	supply, err := token.TotalSupply(ctx)
+	if err != nil {
+		return err
+	}
+	for _, check := range []struct{ what, got, want string }{
+		{"name", token.Name, args["name_"]},
+		{"symbol", token.Symbol, args["symbol_"]},
+		{"decimals", fmt.Sprint(token.Decimals), args["decimals_"]},
+		{"total supply", supply.Value.String(), args["initialSupply"]},
+	} {
+		if check.got != check.want {
+			v.problem("%s is %q, deployed with %q", check.what, check.got, check.want)
+		}
+	}
+	return nil
+}
+
+// artifact returns the .abi file and the decoded creation bytecode of
+// contract from the build.
+func artifact(contract string) ([]byte, []byte, error) {
+	abiFile, err := build.ABIs.ReadFile(contract + ".abi")
+	if err != nil {
+		return nil, nil, fmt.Errorf("no build artifact for %s: %v", contract, err)
+	}
+	binFile, err := build.Bins.ReadFile(contract + ".bin")
+	if err != nil {
+		return nil, nil, fmt.Errorf("no build artifact for %s: %v", contract, err)
+	}
+	bin := common.FromHex(strings.TrimSpace(string(binFile)))
+	if len(bin) == 0 {
+		return nil, nil, fmt.Errorf("build/%s.bin holds no bytecode", contract)
+	}
+	return abiFile, bin, nil
+}
+
+// stripMetadata cuts the CBOR metadata solc appends to code: its length is
+// in the last two bytes. Code without it is returned unchanged.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	n := int(binary.BigEndian.Uint16(code[len(code)-2:])) + 2
+	// a CBOR map of the metadata fields starts the trailer
+	if n > len(code) || code[len(code)-n]&0xf0 != 0xa0 {
+		return code
+	}
+	return code[:len(code)-n]
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/deploy"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// runDeploy deploys a TestERC20 token with the name, symbol, decimals,
+// supply and holder given as flags, 1000 TST held by the deployer by default,
+// and records it in the deployment registry under -as, which must be free
+// unless -replace is given. -test-to sends a test transfer after deploying.
+// With -create2 it goes through the CREATE2 factory, to the same address on
+// every chain for whoever deploys it, so -holder is required.
+func runDeploy(args []string) error {
+	defaults := deploy.DefaultTokenParams()
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	name := fs.String("name", defaults.Name, "token name")
+	symbol := fs.String("symbol", defaults.Symbol, "token symbol")
+	decimals := fs.Uint("decimals", uint(defaults.Decimals), "token decimals")
+	supply := fs.String("supply", units.Unit{Decimals: defaults.Decimals}.Format(defaults.Supply), "tokens minted at deployment, e.g. 1000 or 2.5")
+	holder := fs.String("holder", "", "account the supply is minted to (default the deployer; required with -create2)")
+	as := fs.String("as", registry.DefaultToken, "name to record the deployment under; commands find the token by it with -token")
+	create2 := fs.Bool("create2", false, "deploy through the CREATE2 factory, to an address set by the token parameters and -salt")
+	saltFlag := fs.String("salt", "", "CREATE2 salt: 0x and 64 hex digits, or any text, which is hashed (default zero)")
+	replace := fs.Bool("replace", false, "record the deployment under -as even if that name is taken by another contract")
+	testTo := fs.String("test-to", "", "account to send a test transfer of 10 tokens to after deploying (default none)")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *decimals > 255 {
+		return fmt.Errorf("-decimals: %d is more than 255", *decimals)
+	}
+	params := deploy.TokenParams{Name: *name, Symbol: *symbol, Decimals: uint8(*decimals)}
+	amount, err := params.Unit().Parse(*supply)
+	if err != nil {
+		return fmt.Errorf("-supply: %v", err)
+	}
+	params.Supply = amount.Value
+	if *holder != "" {
+		if params.Holder, err = parseAddress("holder", *holder); err != nil {
+			return err
+		}
+	}
+
+	var to common.Address
+	if *testTo != "" {
+		if to, err = parseAddress("test-to", *testTo); err != nil {
+			return err
+		}
+	}
+
+	var salt *common.Hash
+	if *create2 {
+		// the holder is in the init code: defaulting it to the deployer
+		// would give every deployer a different address
+		if *holder == "" {
+			return errors.New("-create2 needs -holder, which is part of the address")
+		}
+		salt = new(common.Hash)
+		if *saltFlag != "" {
+			*salt = parseSalt(*saltFlag)
+		}
+	} else if *saltFlag != "" {
+		return errors.New("-salt is only for -create2")
+	}
+
+	return deploy.RunTestERC20Contract(context.Background(), cfg, *as, params, salt, to, *replace)
+}
+
+// parseSalt reads a salt given as 0x and 64 hex digits; any other text is
+// hashed with keccak256, so a label such as "v1" gives a salt too.
+func parseSalt(s string) common.Hash {
+	if len(s) == 2+2*common.HashLength && strings.HasPrefix(s, "0x") {
+		if b, err := hexutil.Decode(s); err == nil {
+			return common.BytesToHash(b)
+		}
+	}
+	return crypto.Keccak256Hash([]byte(s))
+}
+
+// runDeployments lists the deployments the registry holds for the chain,
+// -chain-id or the connected one.
+func runDeployments(args []string) error {
+	ctx := context.Background()
+	fs := flag.NewFlagSet("deployments", flag.ExitOnError)
+	chainID := fs.Int64("chain-id", 0, "chain to list the deployments of (default the connected one)")
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	chain := big.NewInt(*chainID)
+	if *chainID <= 0 {
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		session.Close()
+		chain = session.ChainID
+	}
+	r, err := registry.Load(cfg.Paths.DeploymentsFile)
+	if err != nil {
+		return err
+	}
+	names := r.Names(chain)
+	if len(names) == 0 {
+		fmt.Printf("No deployments on chain %s in %s\n", chain, cfg.Paths.DeploymentsFile)
+		return nil
+	}
+	fmt.Printf("Deployments on chain %s:\n", chain)
+	for _, name := range names {
+		d, _ := r.Lookup(chain, name)
+		fmt.Printf("  %-12s %-10s %s  block %d, transaction %s\n", name, d.Contract, d.Address.Hex(), d.Block, d.TxHash.Hex())
+		if d.Factory != nil {
+			fmt.Printf("  %12s CREATE2 through %s, salt %s\n", "", d.Factory.Hex(), d.Salt.Hex())
+		}
+		for _, arg := range d.Args {
+			fmt.Printf("  %12s %s = %s\n", "", arg.Name, arg.Value)
+		}
+	}
+	return nil
+}
+
+// runVerify checks deployments of the registry against the build artifacts:
+// the ones named, or all of them on the connected chain.
+func runVerify(args []string) error {
+	ctx := context.Background()
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	session, err := connection.NewReadOnlySession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	r, err := registry.Load(cfg.Paths.DeploymentsFile)
+	if err != nil {
+		return err
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		if names = r.Names(session.ChainID); len(names) == 0 {
+			return fmt.Errorf("no deployments on chain %s in %s", session.ChainID, cfg.Paths.DeploymentsFile)
+		}
+	}
+
+	failed := 0
+	for _, name := range names {
+		d, ok := r.Lookup(session.ChainID, name)
+		if !ok {
+			return fmt.Errorf("no deployment named %q on chain %s in %s", name, session.ChainID, cfg.Paths.DeploymentsFile)
+		}
+		v, err := deploy.Verify(ctx, session.Client, d)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		fmt.Printf("%s: ", name)
+		v.Print()
+		if !v.OK() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deployments failed verification", failed, len(names))
+	}
+	return nil
+}
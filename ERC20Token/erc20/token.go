@@ -0,0 +1,199 @@
+// Package erc20 talks to any ERC20 token through IERC20Metadata, including
+// the older tokens that return nothing from transfer and approve, or
+// bytes32 from name and symbol.
+package erc20
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// ErrReturnedFalse is returned when a token reports a failed transfer or
+// approval by returning false instead of reverting.
+var ErrReturnedFalse = errors.New("token returned false")
+
+// Token is an ERC20 contract at Address. Name, Symbol and Decimals are read
+// once when it is opened.
+type Token struct {
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals uint8
+
+	backend  bind.ContractBackend
+	abi      *abi.ABI
+	contract *contractsgo.IERC20Metadata
+}
+
+// Open reads the metadata of the token at address. It fails if there is no
+// contract there or it has no decimals.
+func Open(ctx context.Context, backend bind.ContractBackend, address common.Address) (*Token, error) {
+	code, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code of %s: %v", address.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract at %s", address.Hex())
+	}
+
+	parsed, err := contractsgo.IERC20MetadataMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := contractsgo.NewIERC20Metadata(address, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate token %s: %v", address.Hex(), err)
+	}
+	t := &Token{Address: address, backend: backend, abi: parsed, contract: contract}
+
+	if t.Decimals, err = contract.Decimals(&bind.CallOpts{Context: ctx}); err != nil {
+		return nil, fmt.Errorf("failed to get decimals of %s: %v", address.Hex(), err)
+	}
+	// name and symbol are optional in ERC20; leave them empty if missing
+	t.Name, _ = t.text(ctx, "name")
+	t.Symbol, _ = t.text(ctx, "symbol")
+	return t, nil
+}
+
+// Unit is what the token counts in.
+func (t *Token) Unit() units.Unit {
+	return units.Unit{Symbol: t.Symbol, Decimals: t.Decimals}
+}
+
+// TotalSupply returns the tokens in existence.
+func (t *Token) TotalSupply(ctx context.Context) (units.Amount, error) {
+	supply, err := t.contract.TotalSupply(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return units.Amount{}, fmt.Errorf("failed to get total supply: %v", err)
+	}
+	return t.Unit().Amount(supply), nil
+}
+
+// BalanceOf returns the tokens account holds.
+func (t *Token) BalanceOf(ctx context.Context, account common.Address) (units.Amount, error) {
+	return t.BalanceAt(ctx, account, nil)
+}
+
+// BalanceAt returns the tokens account held at block, or now if block is
+// nil.
+func (t *Token) BalanceAt(ctx context.Context, account common.Address, block *big.Int) (units.Amount, error) {
+	balance, err := t.contract.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: block}, account)
+	if err != nil {
+		return units.Amount{}, fmt.Errorf("failed to get token balance of %s: %v", account.Hex(), err)
+	}
+	return t.Unit().Amount(balance), nil
+}
+
+// Allowance returns how many of owner's tokens spender may still move.
+func (t *Token) Allowance(ctx context.Context, owner, spender common.Address) (units.Amount, error) {
+	allowance, err := t.contract.Allowance(&bind.CallOpts{Context: ctx}, owner, spender)
+	if err != nil {
+		return units.Amount{}, fmt.Errorf("failed to get allowance: %v", err)
+	}
+	return t.Unit().Amount(allowance), nil
+}
+
+// Transfer sends a transfer of value to to. Whether it returned true can only
+// be seen in its receipt; see Transfers.
+func (t *Token) Transfer(opts *bind.TransactOpts, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return t.contract.Transfer(opts, to, value)
+}
+
+// TransferFrom sends a transferFrom of value from from to to.
+func (t *Token) TransferFrom(opts *bind.TransactOpts, from, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return t.contract.TransferFrom(opts, from, to, value)
+}
+
+// Approve sends an approval for spender of value.
+func (t *Token) Approve(opts *bind.TransactOpts, spender common.Address, value *big.Int) (*types.Transaction, error) {
+	return t.contract.Approve(opts, spender, value)
+}
+
+// Pack encodes a call of one of the IERC20Metadata methods.
+func (t *Token) Pack(method string, args ...interface{}) ([]byte, error) {
+	return t.abi.Pack(method, args...)
+}
+
+// Call runs a transfer, transferFrom or approve from from as eth_call on the
+// latest state. A revert comes back as the backend's error; a token that
+// returns false gives ErrReturnedFalse, while one that returns nothing at
+// all counts as successful, like OpenZeppelin's SafeERC20 does.
+func (t *Token) Call(ctx context.Context, from common.Address, method string, args ...interface{}) error {
+	data, err := t.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	output, err := t.backend.CallContract(ctx, ethereum.CallMsg{From: from, To: &t.Address, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+	return checkReturn(method, output)
+}
+
+// Estimate estimates the gas of a transfer, transferFrom or approve from
+// from.
+func (t *Token) Estimate(ctx context.Context, from common.Address, method string, args ...interface{}) (uint64, error) {
+	data, err := t.Pack(method, args...)
+	if err != nil {
+		return 0, err
+	}
+	return t.backend.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &t.Address, Data: data})
+}
+
+// Transfers returns the token's Transfer logs in receipt. A successful
+// transfer without one means the token returned false.
+func (t *Token) Transfers(receipt *types.Receipt) []*contractsgo.IERC20MetadataTransfer {
+	var transfers []*contractsgo.IERC20MetadataTransfer
+	for _, log := range receipt.Logs {
+		if log.Address != t.Address {
+			continue
+		}
+		if transfer, err := t.contract.ParseTransfer(*log); err == nil {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers
+}
+
+// text reads name or symbol, as a string or, for tokens such as MKR, as
+// bytes32.
+func (t *Token) text(ctx context.Context, method string) (string, error) {
+	data, err := t.Pack(method)
+	if err != nil {
+		return "", err
+	}
+	output, err := t.backend.CallContract(ctx, ethereum.CallMsg{To: &t.Address, Data: data}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if values, err := t.abi.Unpack(method, output); err == nil {
+		return values[0].(string), nil
+	}
+	if len(output) == 32 {
+		return string(bytes.TrimRight(output, "\x00")), nil
+	}
+	return "", fmt.Errorf("%s of %s is neither string nor bytes32", method, t.Address.Hex())
+}
+
+// checkReturn accepts no return data or an ABI-encoded true.
+func checkReturn(method string, output []byte) error {
+	if len(output) == 0 {
+		return nil
+	}
+	if len(output) != 32 || new(big.Int).SetBytes(output).Cmp(common.Big1) != 0 {
+		return fmt.Errorf("%s: %w", method, ErrReturnedFalse)
+	}
+	return nil
+}
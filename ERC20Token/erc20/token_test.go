@@ -0,0 +1,175 @@
+package erc20
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+)
+
+// answer is what a mock token returns for one method: a single word, or
+// nothing at all if word is nil.
+type answer struct {
+	method string
+	word   []byte
+}
+
+func uint256(n int64) []byte {
+	return common.LeftPadBytes(big.NewInt(n).Bytes(), 32)
+}
+
+func bytes32(s string) []byte {
+	return common.RightPadBytes([]byte(s), 32)
+}
+
+// mockCode assembles a contract that answers each method as given and
+// reverts on any other call, so tokens can be mocked without a compiler.
+func mockCode(answers []answer) []byte {
+	const (
+		header   = 6  // selector from the calldata
+		dispatch = 11 // per method
+		fallback = 4  // revert
+	)
+	var code, bodies []byte
+	code = append(code, 0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c) // PUSH1 0 CALLDATALOAD PUSH1 224 SHR
+	start := header + dispatch*len(answers) + fallback
+	for _, a := range answers {
+		label := make([]byte, 2)
+		binary.BigEndian.PutUint16(label, uint16(start+len(bodies)))
+		code = append(code, 0x80, 0x63) // DUP1 PUSH4
+		code = append(code, crypto.Keccak256([]byte(a.method))[:4]...)
+		code = append(code, 0x14, 0x61, label[0], label[1], 0x57) // EQ PUSH2 label JUMPI
+
+		bodies = append(bodies, 0x5b) // JUMPDEST
+		if a.word == nil {
+			bodies = append(bodies, 0x00) // STOP
+			continue
+		}
+		bodies = append(bodies, 0x7f) // PUSH32
+		bodies = append(bodies, a.word...)
+		bodies = append(bodies, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3) // PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	}
+	code = append(code, 0x60, 0x00, 0x80, 0xfd) // PUSH1 0 DUP1 REVERT
+	runtime := append(code, bodies...)
+
+	// constructor: return the runtime code that follows it
+	size := make([]byte, 2)
+	binary.BigEndian.PutUint16(size, uint16(len(runtime)))
+	constructor := []byte{0x61, size[0], size[1], 0x80, 0x61, 0x00, 0x0d, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(constructor, runtime...)
+}
+
+func newSession(t *testing.T) *connection.Session {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connection.NewSimulatedSession(context.Background(), connection.Options{}, connection.NewKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(session.Close)
+	return session
+}
+
+func deployMock(t *testing.T, session *connection.Session, answers ...answer) common.Address {
+	t.Helper()
+	auth, err := session.NextTransaction(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, _, err := bind.DeployContract(auth, abi.ABI{}, mockCode(answers), session.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func deployTestERC20(t *testing.T, session *connection.Session) common.Address {
+	t.Helper()
+	auth, err := session.NextTransaction(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, _, err := contractsgo.DeployTestERC20(auth, session.Client, "Test", "TST", 6, big.NewInt(1_000_000), session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func TestOpenMetadata(t *testing.T) {
+	session := newSession(t)
+	for _, test := range []struct {
+		name      string
+		address   common.Address
+		tokenName string
+		symbol    string
+		decimals  uint8
+		errText   string
+	}{
+		{"string", deployTestERC20(t, session), "Test", "TST", 6, ""},
+		{"bytes32 like MKR", deployMock(t, session,
+			answer{"name()", bytes32("Maker")},
+			answer{"symbol()", bytes32("MKR")},
+			answer{"decimals()", uint256(18)},
+		), "Maker", "MKR", 18, ""},
+		{"without name and symbol", deployMock(t, session, answer{"decimals()", uint256(8)}), "", "", 8, ""},
+		{"without decimals", deployMock(t, session, answer{"name()", bytes32("Maker")}), "", "", 0, "failed to get decimals"},
+		{"no contract", common.HexToAddress("0x1000000000000000000000000000000000000001"), "", "", 0, "no contract at"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := Open(context.Background(), session.Client, test.address)
+			if test.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errText) {
+					t.Fatalf("error is %v, want one containing %q", err, test.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Name != test.tokenName || token.Symbol != test.symbol || token.Decimals != test.decimals {
+				t.Fatalf("token is %q %q with %d decimals, want %q %q with %d", token.Name, token.Symbol, token.Decimals, test.tokenName, test.symbol, test.decimals)
+			}
+		})
+	}
+}
+
+func TestCallReturn(t *testing.T) {
+	session := newSession(t)
+	decimals := answer{"decimals()", uint256(6)}
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	for _, test := range []struct {
+		name    string
+		address common.Address
+		value   int64
+		errText string
+	}{
+		{"returns true", deployTestERC20(t, session), 10, ""},
+		{"reverts", deployTestERC20(t, session), 2_000_000, "execution reverted"},
+		{"returns nothing like USDT", deployMock(t, session, decimals, answer{"transfer(address,uint256)", nil}), 10, ""},
+		{"returns false", deployMock(t, session, decimals, answer{"transfer(address,uint256)", uint256(0)}), 10, ErrReturnedFalse.Error()},
+		{"returns something else", deployMock(t, session, decimals, answer{"transfer(address,uint256)", uint256(2)}), 10, ErrReturnedFalse.Error()},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := Open(context.Background(), session.Client, test.address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = token.Call(context.Background(), session.From, "transfer", to, big.NewInt(test.value))
+			if test.errText == "" && err != nil || test.errText != "" && (err == nil || !strings.Contains(err.Error(), test.errText)) {
+				t.Fatalf("error is %v, want %q", err, test.errText)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package interact
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// Allowance returns how many of owner's tokens spender may still move.
+func Allowance(ctx context.Context, client bind.ContractBackend, contractAddress string, owner, spender common.Address) (units.Amount, error) {
+	token, err := OpenToken(ctx, client, contractAddress)
+	if err != nil {
+		return units.Amount{}, err
+	}
+	return token.Allowance(ctx, owner, spender)
+}
+
+// Approve lets spender move up to value of the session account's tokens,
+// replacing any earlier allowance; zero revokes it. It waits until the
+// approval is mined and confirmed.
+func Approve(ctx context.Context, session *connection.Session, contractAddress string, spender common.Address, value units.Amount) (string, error) {
+	if err := session.CheckWritable(); err != nil {
+		return "", err
+	}
+
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return "", err
+	}
+	// like transfer, approve may return false instead of reverting
+	if err := token.Call(ctx, session.From, "approve", spender, value.Value); err != nil {
+		return "", fmt.Errorf("approval of %s would fail: %w", spender.Hex(), reverts.Wrap(err))
+	}
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return "", err
+	}
+	tx, err := token.Approve(auth, spender, value.Value)
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return "", fmt.Errorf("failed to approve %s: %w", spender.Hex(), reverts.Wrap(err))
+	}
+
+	if _, err := waitTransaction(ctx, session, tx); err != nil {
+		return "", err
+	}
+	fmt.Printf("Approved %s to spend %s of %s\n", spender.Hex(), value, session.From.Hex())
+	return tx.Hash().Hex(), nil
+}
+
+// TransferFrom sends value of owner's tokens to toAddress, spending the
+// allowance owner gave the session account. The owner's key is not needed.
+// Like TransferTokens, a reverted transfer comes with a *RevertError.
+func TransferFrom(ctx context.Context, session *connection.Session, contractAddress string, owner, toAddress common.Address, value units.Amount) (*TransferResult, error) {
+	return transferFrom(ctx, session, contractAddress, owner, toAddress, value, nil)
+}
+
+// transferFrom is TransferFrom, handing the transaction to onSigned, if set,
+// as connection.OnSigned does.
+func transferFrom(ctx context.Context, session *connection.Session, contractAddress string, owner, toAddress common.Address, value units.Amount, onSigned func(*types.Transaction) error) (*TransferResult, error) {
+	if err := session.CheckWritable(); err != nil {
+		return nil, err
+	}
+
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// a short allowance would only show up as a revert
+	allowance, err := token.Allowance(ctx, owner, session.From)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Value.Cmp(value.Value) < 0 {
+		return nil, fmt.Errorf("allowance of %s from %s is %s, %s needed", session.From.Hex(), owner.Hex(), allowance, value)
+	}
+	if err := token.Call(ctx, session.From, "transferFrom", owner, toAddress, value.Value); err != nil {
+		return nil, fmt.Errorf("transfer from %s would fail: %w", owner.Hex(), reverts.Wrap(err))
+	}
+
+	fmt.Printf("Transferring %s from %s...\n", value, owner.Hex())
+	accounts := []common.Address{owner, session.From, toAddress}
+	labels := []string{"Owner", "Spender", "Receiver"}
+	before := snapshotAccounts(ctx, session.Client, token, nil, accounts, labels)
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if onSigned != nil {
+		connection.OnSigned(auth, onSigned)
+	}
+	tx, err := token.TransferFrom(auth, owner, toAddress, value.Value)
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return nil, fmt.Errorf("failed to transfer tokens from %s: %w", owner.Hex(), reverts.Wrap(err))
+	}
+
+	receipt, err := waitTransaction(ctx, session, tx)
+	if receipt == nil {
+		return nil, err
+	}
+	result, resultErr := newTransferResult(ctx, session, token, tx, receipt)
+	if resultErr != nil {
+		return nil, resultErr
+	}
+	result.Balances = balanceChanges(ctx, session.Client, token, before, receipt, accounts, labels)
+	result.print()
+	if err != nil {
+		return result, err
+	}
+	if err := result.checkTransferred(); err != nil {
+		return result, err
+	}
+
+	fmt.Printf("Remaining allowance: %s\n", value.Unit.Format(new(big.Int).Sub(allowance.Value, value.Value)))
+
+	return result, nil
+}
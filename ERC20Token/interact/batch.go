@@ -0,0 +1,318 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/journal"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// DefaultBatchRecipients caps the recipients of one disperse transaction when
+// BatchOptions doesn't.
+const DefaultBatchRecipients = 500
+
+// BatchOptions tunes how BatchTransfer splits the recipient list.
+type BatchOptions struct {
+	MaxRecipients int    // per transaction, default DefaultBatchRecipients
+	GasBudget     uint64 // per transaction, default half the block gas limit
+
+	// Journal, if set, records every transfer under Keys[i] like
+	// TransferOnce does, and recipients it shows as paid are left out.
+	Journal *journal.Journal
+	Keys    []journal.Key
+}
+
+// ChunkResult reports one disperse transaction of a batch: the recipients
+// recipients[First:First+Count] and the tokens sent to them. With a journal,
+// recipients are those not paid before. Err is set if
+// the chunk could not be sent, and is a *RevertError if it reverted.
+type ChunkResult struct {
+	First   int
+	Count   int
+	Total   units.Amount
+	TxHash  common.Hash
+	GasUsed uint64
+	Err     error
+}
+
+// BatchTransfer sends amounts[i] to recipients[i] through the Disperse
+// contract at disperseAddress, in as few transactions as fit opts.GasBudget. It
+// approves Disperse for the total first if the allowance is short. A failed
+// chunk doesn't stop the ones after it; the error returned then counts the
+// failed chunks, whose recipients can be found in the results.
+func BatchTransfer(ctx context.Context, session *connection.Session, contractAddress, disperseAddress string, recipients []common.Address, amounts []units.Amount, opts BatchOptions) ([]ChunkResult, error) {
+	if err := session.CheckWritable(); err != nil {
+		return nil, err
+	}
+	if len(recipients) != len(amounts) {
+		return nil, fmt.Errorf("%d recipients but %d amounts", len(recipients), len(amounts))
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	if opts.Journal != nil && len(opts.Keys) != len(recipients) {
+		return nil, fmt.Errorf("%d recipients but %d journal keys", len(recipients), len(opts.Keys))
+	}
+
+	values := make([]*big.Int, len(amounts))
+	for i, amount := range amounts {
+		if amount.Value == nil || amount.Value.Sign() <= 0 {
+			return nil, fmt.Errorf("amount for %s must be positive", recipients[i].Hex())
+		}
+		values[i] = amount.Value
+	}
+
+	disperseAddr := common.HexToAddress(disperseAddress)
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Journal != nil {
+		var keys []journal.Key
+		var pending []common.Address
+		var pendingValues []*big.Int
+		for i, key := range opts.Keys {
+			err := resolve(ctx, session, opts.Journal, token, key, values[i])
+			if errors.Is(err, ErrAlreadyTransferred) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			keys, pending, pendingValues = append(keys, key), append(pending, recipients[i]), append(pendingValues, values[i])
+		}
+		if skipped := len(recipients) - len(pending); skipped > 0 {
+			fmt.Printf("Skipping %d recipients the journal shows as paid\n", skipped)
+		}
+		if len(pending) == 0 {
+			return nil, nil
+		}
+		opts.Keys, recipients, values = keys, pending, pendingValues
+	}
+	disperse, err := contractsgo.NewDisperse(disperseAddr, session.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate Disperse contract: %v", err)
+	}
+
+	unit := token.Unit()
+	total := unit.Amount(sum(values))
+
+	balance, err := token.BalanceOf(ctx, session.From)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Value.Cmp(total.Value) < 0 {
+		return nil, fmt.Errorf("batch needs %s but %s holds %s", total, session.From.Hex(), balance)
+	}
+
+	if opts.MaxRecipients <= 0 {
+		opts.MaxRecipients = DefaultBatchRecipients
+	}
+	if opts.GasBudget == 0 {
+		head, err := session.Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block gas limit: %v", err)
+		}
+		opts.GasBudget = head.GasLimit / 2
+	}
+
+	allowance, err := Allowance(ctx, session.Client, contractAddress, session.From, disperseAddr)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Value.Cmp(total.Value) < 0 {
+		if _, err := Approve(ctx, session, contractAddress, disperseAddr, total); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("Sending %s to %d recipients in batches...\n", total, len(recipients))
+	var results []ChunkResult
+	failed := 0
+	size := opts.MaxRecipients
+	for first := 0; first < len(recipients); {
+		count, gas, err := fitChunk(ctx, session, token.Address, disperseAddr, recipients[first:], values[first:], size, opts.GasBudget)
+		if err != nil {
+			// without an estimate there is no size to skip by; stop here
+			results = append(results, ChunkResult{First: first, Count: len(recipients) - first, Total: unit.Amount(sum(values[first:])), Err: err})
+			failed++
+			printChunk(session, len(results), results[len(results)-1])
+			break
+		}
+
+		result := ChunkResult{First: first, Count: count, Total: unit.Amount(sum(values[first : first+count]))}
+		var onSigned func(*types.Transaction) error
+		var signed *types.Transaction
+		if opts.Journal != nil {
+			onSigned = func(tx *types.Transaction) error {
+				for i := first; i < first+count; i++ {
+					base := journal.Entry{Token: token.Address, From: session.From, Amount: values[i]}
+					if err := opts.Journal.Record(opts.Keys[i], signedEntry(base, tx)); err != nil {
+						return err
+					}
+				}
+				signed = tx
+				return nil
+			}
+		}
+		var receipt *types.Receipt
+		receipt, result.Err = sendChunk(ctx, session, disperse, token.Address, recipients[first:first+count], values[first:first+count], gas, onSigned)
+		if signed != nil {
+			result.TxHash = signed.Hash()
+		}
+		if receipt != nil {
+			result.TxHash, result.GasUsed = receipt.TxHash, receipt.GasUsed
+		}
+		if opts.Journal != nil {
+			if err := recordChunk(opts.Journal, token, opts.Keys[first:first+count], values[first:first+count], session.From, signed, receipt, result.Err); err != nil && result.Err == nil {
+				result.Err = err
+			}
+		}
+		if result.Err != nil {
+			failed++
+		}
+		results = append(results, result)
+		printChunk(session, len(results), result)
+
+		if ctx.Err() != nil {
+			break
+		}
+		// the next chunk costs about the same; start from what fit
+		size = count
+		first += count
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d batch transactions failed", failed, len(results))
+	}
+	return results, nil
+}
+
+// fitChunk returns how many of the leading recipients, at most size, one
+// transaction can take within budget, and its gas estimate. It shrinks the
+// chunk in proportion to each estimate until it fits.
+func fitChunk(ctx context.Context, session *connection.Session, token, disperse common.Address, recipients []common.Address, amounts []*big.Int, size int, budget uint64) (int, uint64, error) {
+	parsed, err := contractsgo.DisperseMetaData.GetAbi()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count := size
+	if count > len(recipients) {
+		count = len(recipients)
+	}
+	for {
+		data, err := parsed.Pack("disperseToken", token, recipients[:count], amounts[:count])
+		if err != nil {
+			return 0, 0, err
+		}
+		gas, err := session.Client.EstimateGas(ctx, ethereum.CallMsg{From: session.From, To: &disperse, Data: data})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to estimate gas for %d transfers: %w", count, reverts.Wrap(err))
+		}
+		if gas <= budget {
+			return count, gas, nil
+		}
+		if count == 1 {
+			return 0, 0, fmt.Errorf("a single transfer needs %d gas, over the budget of %d", gas, budget)
+		}
+
+		next := int(uint64(count) * budget / gas)
+		if next >= count {
+			next = count - 1
+		}
+		if next < 1 {
+			next = 1
+		}
+		count = next
+	}
+}
+
+// sendChunk sends one disperse transaction and waits for it to be mined and
+// confirmed. The receipt is returned once it is mined, even with an error.
+// onSigned, if set, gets the transaction before it is sent.
+func sendChunk(ctx context.Context, session *connection.Session, disperse *contractsgo.Disperse, token common.Address, recipients []common.Address, amounts []*big.Int, gas uint64, onSigned func(*types.Transaction) error) (*types.Receipt, error) {
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if onSigned != nil {
+		connection.OnSigned(auth, onSigned)
+	}
+	// estimates of loops are close; leave a little room for state changes
+	auth.GasLimit = gas + gas/10
+
+	tx, err := disperse.DisperseToken(auth, token, recipients, amounts)
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return nil, fmt.Errorf("failed to send batch: %w", reverts.Wrap(err))
+	}
+
+	receipt, err := bind.WaitMined(ctx, session.Client, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, newRevertError(ctx, session, tx, receipt)
+	}
+	if err := session.WaitConfirmations(ctx, receipt.BlockNumber); err != nil {
+		return receipt, fmt.Errorf("failed waiting for confirmations of %s: %v", tx.Hash().Hex(), err)
+	}
+	return receipt, nil
+}
+
+// recordChunk records the outcome of a disperse transaction for each of its
+// journal keys, like recordOutcome does for single transfers.
+func recordChunk(j *journal.Journal, token *erc20.Token, keys []journal.Key, amounts []*big.Int, from common.Address, signed *types.Transaction, receipt *types.Receipt, err error) error {
+	for i, key := range keys {
+		entry := journal.Entry{Token: token.Address, From: from, Amount: amounts[i]}
+		if signed != nil {
+			entry = signedEntry(entry, signed)
+		}
+		switch {
+		case receipt != nil && receipt.Status == types.ReceiptStatusSuccessful && paid(token, receipt, key.Recipient):
+			entry.State = journal.Transferred
+		case receipt != nil || signed == nil:
+			entry.State, entry.Error = journal.Failed, failure(err)
+		default:
+			continue
+		}
+		if err := j.Record(key, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printChunk(session *connection.Session, n int, result ChunkResult) {
+	fmt.Printf("Batch %d: recipients %d-%d, %s", n, result.First+1, result.First+result.Count, result.Total)
+	if result.TxHash != (common.Hash{}) {
+		fmt.Printf(", transaction 0x%x, gas used %d", result.TxHash, result.GasUsed)
+	}
+	if result.Err != nil {
+		fmt.Printf(", FAILED: %v", result.Err)
+	}
+	fmt.Println()
+	if url := session.Profile.TxURL(result.TxHash); url != "" && result.TxHash != (common.Hash{}) {
+		fmt.Println("Explorer:", url)
+	}
+}
+
+func sum(amounts []*big.Int) *big.Int {
+	total := new(big.Int)
+	for _, amount := range amounts {
+		total.Add(total, amount)
+	}
+	return total
+}
@@ -0,0 +1,100 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// batchRecipients returns n distinct recipients and an amount of i+1 tokens
+// for the i-th.
+func batchRecipients(unit units.Unit, n int) ([]common.Address, []units.Amount) {
+	recipients := make([]common.Address, n)
+	amounts := make([]units.Amount, n)
+	for i := range recipients {
+		recipients[i] = common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		amounts[i] = unit.Tokens(int64(i + 1))
+	}
+	return recipients, amounts
+}
+
+// A gas budget that takes a few transfers splits the list into chunks that
+// each stay within it and together pay everyone once.
+func TestBatchTransferGasFitted(t *testing.T) {
+	ctx := context.Background()
+	session, token := newTokenChain(t)
+	disperse := deployDisperse(t, session)
+	unit, err := TokenUnit(ctx, session.Client, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipients, amounts := batchRecipients(unit, 10)
+
+	const budget = 150_000
+	results, err := BatchTransfer(ctx, session, token, disperse, recipients, amounts, BatchOptions{GasBudget: budget})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) < 2 {
+		t.Fatalf("%d chunks, want the list split by the gas budget", len(results))
+	}
+	next := 0
+	for i, result := range results {
+		if result.First != next || result.Count == 0 || result.GasUsed > budget || result.Err != nil {
+			t.Errorf("chunk %d is %+v, want one from %d within %d gas", i+1, result, next, budget)
+		}
+		next += result.Count
+	}
+	if next != len(recipients) {
+		t.Errorf("chunks cover %d recipients, want %d", next, len(recipients))
+	}
+	for i, to := range recipients {
+		if got := balanceOf(t, session, token, to); got.Cmp(amounts[i].Value) != 0 {
+			t.Errorf("%s holds %s, want %s", to.Hex(), got, amounts[i].Value)
+		}
+	}
+}
+
+// A chunk whose estimate reverts, here on a transfer to the zero address,
+// fails with the token's revert, and with no estimate to size the next chunk
+// by the batch stops there; the chunks before it stay paid.
+func TestBatchTransferFailedChunk(t *testing.T) {
+	ctx := context.Background()
+	session, token := newTokenChain(t)
+	disperse := deployDisperse(t, session)
+	unit, err := TokenUnit(ctx, session.Client, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipients, amounts := batchRecipients(unit, 5)
+	recipients[3] = common.Address{}
+
+	results, err := BatchTransfer(ctx, session, token, disperse, recipients, amounts, BatchOptions{MaxRecipients: 2})
+	if err == nil || err.Error() != "1 of 2 batch transactions failed" {
+		t.Fatalf("error is %v, want 1 of 2 failed", err)
+	}
+	if results[0].Err != nil || results[0].Count != 2 {
+		t.Errorf("first chunk is %+v, want 2 paid", results[0])
+	}
+	var invalid *reverts.ERC20InvalidReceiver
+	if failed := results[1]; failed.First != 2 || failed.Count != 3 || !errors.As(failed.Err, &invalid) {
+		t.Errorf("second chunk is %+v, want recipients 3-5 failed with ERC20InvalidReceiver", failed)
+	}
+
+	for i, to := range recipients {
+		want := new(big.Int)
+		if i < 2 {
+			want = amounts[i].Value
+		}
+		if to != (common.Address{}) {
+			if got := balanceOf(t, session, token, to); got.Cmp(want) != 0 {
+				t.Errorf("%s holds %s, want %s", to.Hex(), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package interact
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// OfflineParams pins everything a transaction otherwise asks the node for,
+// so that transfers can be signed on a machine without one. Either GasPrice
+// (legacy) or GasFeeCap and GasTipCap (EIP-1559) are set.
+type OfflineParams struct {
+	ChainID   *big.Int
+	Nonce     uint64 // nonce of the first transfer; the others follow on
+	GasLimit  uint64 // of every transfer
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+// SignedTransfer is a signed transfer ready to be broadcast.
+type SignedTransfer struct {
+	To     common.Address
+	Amount units.Amount
+	Tx     *types.Transaction
+}
+
+// SignTransfers signs transfers of amounts[i] of the token at token to
+// recipients[i] with signer, numbered from params.Nonce. It needs no node:
+// nothing is estimated or checked against the chain, so the transfers
+// should be simulated first.
+func SignTransfers(ctx context.Context, signer connection.Signer, token common.Address, params OfflineParams, recipients []common.Address, amounts []units.Amount) ([]SignedTransfer, error) {
+	if len(recipients) != len(amounts) {
+		return nil, fmt.Errorf("%d recipients but %d amounts", len(recipients), len(amounts))
+	}
+	if params.ChainID == nil || params.ChainID.Sign() <= 0 {
+		return nil, errors.New("offline signing needs the chain ID")
+	}
+	if params.GasLimit == 0 {
+		return nil, errors.New("offline signing needs a gas limit")
+	}
+	if params.GasPrice == nil && (params.GasFeeCap == nil || params.GasTipCap == nil) {
+		return nil, errors.New("offline signing needs a gas price, or a max fee and priority fee")
+	}
+
+	parsed, err := contractsgo.IERC20MetadataMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	signed := make([]SignedTransfer, len(recipients))
+	for i, to := range recipients {
+		data, err := parsed.Pack("transfer", to, amounts[i].Value)
+		if err != nil {
+			return nil, err
+		}
+
+		var tx *types.Transaction
+		nonce := params.Nonce + uint64(i)
+		if params.GasPrice != nil {
+			tx = types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: params.GasPrice, Gas: params.GasLimit, To: &token, Data: data})
+		} else {
+			tx = types.NewTx(&types.DynamicFeeTx{ChainID: params.ChainID, Nonce: nonce, GasTipCap: params.GasTipCap, GasFeeCap: params.GasFeeCap, Gas: params.GasLimit, To: &token, Data: data})
+		}
+		if tx, err = signer.SignTx(ctx, tx, params.ChainID); err != nil {
+			return nil, fmt.Errorf("failed to sign transfer to %s: %v", to.Hex(), err)
+		}
+		signed[i] = SignedTransfer{To: to, Amount: amounts[i], Tx: tx}
+	}
+	return signed, nil
+}
+
+// WriteSignedTransfers writes one hex-encoded raw transaction per line, each
+// after a comment saying what it does.
+func WriteSignedTransfers(path string, transfers []SignedTransfer) error {
+	var b strings.Builder
+	for _, transfer := range transfers {
+		raw, err := transfer.Tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "# nonce %d: %s to %s\n", transfer.Tx.Nonce(), transfer.Amount, transfer.To.Hex())
+		fmt.Fprintln(&b, hexutil.Encode(raw))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write signed transactions: %v", err)
+	}
+	return nil
+}
+
+// ReadSignedTransactions reads a file written by WriteSignedTransfers;
+// blank lines and lines starting with # are skipped. Contract creations are
+// refused, as only token transfers can be broadcast.
+func ReadSignedTransactions(path string) ([]*types.Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signed transactions: %v", err)
+	}
+	defer f.Close()
+
+	var txs []*types.Transaction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		raw, err := hexutil.Decode(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("%s:%d: not a transaction: %v", path, line, err)
+		}
+		if tx.To() == nil {
+			return nil, fmt.Errorf("%s:%d: %s creates a contract, not a transfer", path, line, tx.Hash().Hex())
+		}
+		txs = append(txs, tx)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read signed transactions: %v", err)
+	}
+	return txs, nil
+}
+
+// BroadcastResult is the outcome of one broadcast transaction. Result is set
+// once it is mined; Err if it could not be sent, reverted or moved no tokens.
+// Mined is set for transactions that were already mined before this
+// broadcast.
+type BroadcastResult struct {
+	TxHash common.Hash
+	Nonce  uint64
+	Mined  bool
+	Result *TransferResult
+	Err    error
+}
+
+// Broadcast sends transactions signed elsewhere and waits for each to be
+// mined and confirmed. A transaction the node already knows, or that was
+// already mined, is not sent again, so a broadcast that was interrupted can
+// simply be run again. Transactions for another chain, and contract
+// creations, are refused. sent, if set, is called with each transaction as
+// soon as the node has it, before anything is awaited; an error from it
+// stops the broadcast.
+func Broadcast(ctx context.Context, session *connection.Session, txs []*types.Transaction, sent func(*types.Transaction) error) ([]BroadcastResult, error) {
+	if err := session.CheckWritable(); err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if tx.To() == nil {
+			return nil, fmt.Errorf("transaction %s creates a contract, not a transfer", tx.Hash().Hex())
+		}
+		if tx.Protected() && tx.ChainId().Cmp(session.ChainID) != 0 {
+			return nil, fmt.Errorf("transaction %s is for chain %s, not %s", tx.Hash().Hex(), tx.ChainId(), session.ChainID)
+		}
+	}
+
+	// send everything first: the nonces are consecutive, so the node can
+	// mine them all while the receipts are awaited one by one
+	results := make([]BroadcastResult, len(txs))
+	next := make(map[common.Address]uint64)
+	for i, tx := range txs {
+		results[i] = BroadcastResult{TxHash: tx.Hash(), Nonce: tx.Nonce()}
+		if receipt, err := session.Client.TransactionReceipt(ctx, tx.Hash()); err == nil && receipt != nil {
+			results[i].Mined = true
+			continue
+		}
+
+		// a transaction after a nonce gap would sit in the node's queue
+		// and never be mined
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			results[i].Err = fmt.Errorf("invalid signature: %v", err)
+			continue
+		}
+		if _, ok := next[sender]; !ok {
+			if next[sender], err = session.Client.PendingNonceAt(ctx, sender); err != nil {
+				return nil, fmt.Errorf("failed to get nonce of %s: %v", sender.Hex(), err)
+			}
+		}
+		if tx.Nonce() > next[sender] {
+			results[i].Err = fmt.Errorf("nonce %d is ahead of %s's next nonce %d", tx.Nonce(), sender.Hex(), next[sender])
+			continue
+		}
+
+		if err := session.Client.SendTransaction(ctx, tx); err != nil && !isKnownTransaction(err) {
+			results[i].Err = fmt.Errorf("failed to send: %v", err)
+			continue
+		}
+		if tx.Nonce() == next[sender] {
+			next[sender]++
+		}
+		fmt.Printf("Sent %s (nonce %d)\n", tx.Hash().Hex(), tx.Nonce())
+		if sent != nil {
+			if err := sent(tx); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	tokens := make(map[common.Address]*erc20.Token)
+	for i, tx := range txs {
+		if results[i].Err != nil {
+			continue
+		}
+		token, ok := tokens[*tx.To()]
+		if !ok {
+			var err error
+			if token, err = erc20.Open(ctx, session.Client, *tx.To()); err != nil {
+				return results, err
+			}
+			tokens[*tx.To()] = token
+		}
+
+		receipt, err := waitTransaction(ctx, session, tx)
+		if receipt == nil {
+			results[i].Err = err
+			continue
+		}
+		result, resultErr := newTransferResult(ctx, session, token, tx, receipt)
+		if resultErr != nil {
+			return results, resultErr
+		}
+		result.print()
+		if err == nil {
+			err = result.checkTransferred()
+		}
+		results[i].Result, results[i].Err = result, err
+	}
+	return results, nil
+}
+
+// isKnownTransaction reports whether err says the node already has the
+// transaction, which for a rebroadcast means there is nothing to do.
+func isKnownTransaction(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
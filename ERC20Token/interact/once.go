@@ -0,0 +1,198 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/journal"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// ErrAlreadyTransferred is returned for a line item the journal and the
+// chain show as paid.
+var ErrAlreadyTransferred = errors.New("already transferred")
+
+// OpenJournal opens the journal at path for the session's chain, so that
+// line items paid on another chain, or on an earlier run of a development
+// chain, are paid again here.
+func OpenJournal(ctx context.Context, session *connection.Session, path string) (*journal.Journal, error) {
+	genesis, err := session.Client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genesis block: %v", err)
+	}
+	return journal.Open(path, journal.Chain{ID: session.ChainID.Uint64(), Genesis: genesis.Hash()})
+}
+
+// TransferOnce is TransferTokens of value to key.Recipient, unless the
+// journal shows that line item as paid. The transaction is recorded in j
+// before it is sent, so a run that crashes at any point and is repeated
+// checks on the chain before paying again; one still pending is waited for.
+func TransferOnce(ctx context.Context, session *connection.Session, j *journal.Journal, key journal.Key, contractAddress string, value units.Amount) (*TransferResult, error) {
+	return once(ctx, session, j, key, contractAddress, value, func(onSigned func(*types.Transaction) error) (*TransferResult, error) {
+		return transferTokens(ctx, session, contractAddress, key.Recipient, value, onSigned)
+	})
+}
+
+// TransferFromOnce is TransferFrom of owner's tokens to key.Recipient with
+// the journal checks of TransferOnce.
+func TransferFromOnce(ctx context.Context, session *connection.Session, j *journal.Journal, key journal.Key, contractAddress string, owner common.Address, value units.Amount) (*TransferResult, error) {
+	return once(ctx, session, j, key, contractAddress, value, func(onSigned func(*types.Transaction) error) (*TransferResult, error) {
+		return transferFrom(ctx, session, contractAddress, owner, key.Recipient, value, onSigned)
+	})
+}
+
+// once runs transfer unless key was paid, journaling the transaction transfer
+// hands to its onSigned before sending it.
+func once(ctx context.Context, session *connection.Session, j *journal.Journal, key journal.Key, contractAddress string, value units.Amount, transfer func(onSigned func(*types.Transaction) error) (*TransferResult, error)) (*TransferResult, error) {
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolve(ctx, session, j, token, key, value.Value); err != nil {
+		return nil, err
+	}
+
+	base := journal.Entry{Token: token.Address, From: session.From, Amount: value.Value}
+	var signed *types.Transaction
+	result, err := transfer(func(tx *types.Transaction) error {
+		if err := j.Record(key, signedEntry(base, tx)); err != nil {
+			return err
+		}
+		signed = tx
+		return nil
+	})
+	if recordErr := recordOutcome(j, key, base, signed, result, err); recordErr != nil && err == nil {
+		err = recordErr
+	}
+	return result, err
+}
+
+// resolve returns ErrAlreadyTransferred (wrapped) if key was paid, and nil
+// if it still has to be. A transaction the journal has for key but whose
+// fate it doesn't know is looked up on the chain first, and waited for if
+// it may still be mined.
+func resolve(ctx context.Context, session *connection.Session, j *journal.Journal, token *erc20.Token, key journal.Key, value *big.Int) error {
+	entry, ok := j.Lookup(key)
+	if !ok {
+		return nil
+	}
+	if entry.Token != token.Address || entry.Amount == nil || entry.Amount.Cmp(value) != 0 {
+		return fmt.Errorf("%s is journaled as %s base units of %s, not %s of %s", key, entry.Amount, entry.Token.Hex(), value, token.Address.Hex())
+	}
+
+	switch entry.State {
+	case journal.Transferred:
+		return fmt.Errorf("%s: %w in %s", key, ErrAlreadyTransferred, entry.TxHash.Hex())
+	case journal.Failed:
+		return nil
+	}
+
+	// signed: sent or not, mined or not
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for waited := false; ; waited = true {
+		receipt, err := session.Client.TransactionReceipt(ctx, entry.TxHash)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to look up %s of %s: %v", entry.TxHash.Hex(), key, err)
+		}
+		if receipt != nil {
+			if receipt.Status == types.ReceiptStatusSuccessful && paid(token, receipt, key.Recipient) {
+				entry.State, entry.Error = journal.Transferred, ""
+				if err := j.Record(key, entry); err != nil {
+					return err
+				}
+				return fmt.Errorf("%s: %w in %s", key, ErrAlreadyTransferred, entry.TxHash.Hex())
+			}
+			entry.State, entry.Error = journal.Failed, "mined without moving tokens"
+			return j.Record(key, entry)
+		}
+
+		// not mined: once another transaction took its nonce it never will be
+		mined, err := session.Client.NonceAt(ctx, entry.From, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce of %s: %v", entry.From.Hex(), err)
+		}
+		if mined > entry.Nonce {
+			// it may have been mined after its receipt was looked up
+			_, err := session.Client.TransactionReceipt(ctx, entry.TxHash)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				return fmt.Errorf("failed to look up %s of %s: %v", entry.TxHash.Hex(), key, err)
+			}
+			entry.State, entry.Error = journal.Failed, "nonce taken by another transaction"
+			return j.Record(key, entry)
+		}
+		pending, err := session.Client.PendingNonceAt(ctx, entry.From)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce of %s: %v", entry.From.Hex(), err)
+		}
+		if pending <= entry.Nonce {
+			entry.State, entry.Error = journal.Failed, "never reached the node"
+			return j.Record(key, entry)
+		}
+
+		if !waited {
+			fmt.Printf("%s: waiting for pending transaction %s\n", key, entry.TxHash.Hex())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// paid reports whether receipt has a Transfer of the token to recipient.
+func paid(token *erc20.Token, receipt *types.Receipt, recipient common.Address) bool {
+	for _, transfer := range token.Transfers(receipt) {
+		if transfer.To == recipient {
+			return true
+		}
+	}
+	return false
+}
+
+func signedEntry(base journal.Entry, tx *types.Transaction) journal.Entry {
+	entry := base
+	entry.State, entry.TxHash, entry.Nonce = journal.Signed, tx.Hash(), tx.Nonce()
+	return entry
+}
+
+// recordOutcome records how a transfer attempt ended. A transfer that moved
+// the tokens is done even if waiting for its confirmations failed. One that
+// was signed but not seen mined stays signed, as it may still be: the next
+// attempt finds out on the chain.
+func recordOutcome(j *journal.Journal, key journal.Key, base journal.Entry, signed *types.Transaction, result *TransferResult, err error) error {
+	entry := base
+	if signed != nil {
+		entry = signedEntry(base, signed)
+	}
+	switch {
+	case result != nil && result.Status == types.ReceiptStatusSuccessful && len(result.Transfers) > 0:
+		entry.State = journal.Transferred
+	case result != nil || signed == nil:
+		entry.State, entry.Error = journal.Failed, failure(err)
+	default:
+		return nil
+	}
+	return j.Record(key, entry)
+}
+
+// failure describes why a transfer that moved no tokens failed, for the
+// journal.
+func failure(err error) string {
+	if err == nil {
+		return "no tokens moved"
+	}
+	return err.Error()
+}
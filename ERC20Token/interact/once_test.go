@@ -0,0 +1,115 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/journal"
+)
+
+// newTokenChain starts a simulated chain with a fresh session account that
+// holds the whole supply of a TestERC20 with 18 decimals: 1,000 tokens. It
+// returns the session and the token's address.
+func newTokenChain(t *testing.T) (*connection.Session, string) {
+	t.Helper()
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connection.NewSimulatedSession(ctx, connection.Options{}, connection.NewKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(session.Close)
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	supply := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	address, _, _, err := contractsgo.DeployTestERC20(auth, session.Client, "Test", "TST", 18, supply, session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session, address.Hex()
+}
+
+// balanceOf returns the tokens account holds, in base units.
+func balanceOf(t *testing.T, session *connection.Session, token string, account common.Address) *big.Int {
+	t.Helper()
+	erc20, err := OpenToken(context.Background(), session.Client, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, err := erc20.BalanceOf(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance.Value
+}
+
+// A campaign run again on another chain, as on a development chain started
+// afresh, pays there too; the journal only skips what was paid on the chain
+// it runs on.
+func TestTransferOnceOnSecondChain(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	recipient := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	key := journal.Key{Campaign: "spring", Line: 1, Recipient: recipient}
+
+	pay := func(session *connection.Session, token string) error {
+		t.Helper()
+		j, err := OpenJournal(ctx, session, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer j.Close()
+		unit, err := TokenUnit(ctx, session.Client, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = TransferOnce(ctx, session, j, key, token, unit.Tokens(5))
+		return err
+	}
+
+	first, firstToken := newTokenChain(t)
+	second, secondToken := newTokenChain(t)
+	for _, run := range []struct {
+		name    string
+		session *connection.Session
+		token   string
+		paid    bool // before this run
+	}{
+		{"first chain", first, firstToken, false},
+		{"first chain again", first, firstToken, true},
+		{"second chain", second, secondToken, false},
+		{"second chain again", second, secondToken, true},
+		{"back on the first chain", first, firstToken, true},
+	} {
+		err := pay(run.session, run.token)
+		if run.paid && !errors.Is(err, ErrAlreadyTransferred) {
+			t.Fatalf("%s: error is %v, want %v", run.name, err, ErrAlreadyTransferred)
+		}
+		if !run.paid && err != nil {
+			t.Fatalf("%s: %v", run.name, err)
+		}
+	}
+
+	want := new(big.Int).Mul(big.NewInt(5), big.NewInt(1e18))
+	for _, chain := range []struct {
+		session *connection.Session
+		token   string
+	}{{first, firstToken}, {second, secondToken}} {
+		if got := balanceOf(t, chain.session, chain.token, recipient); got.Cmp(want) != 0 {
+			t.Errorf("recipient holds %s on chain with token %s, want %s", got, chain.token, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+)
+
+// TransferResult is the outcome of a mined token transaction.
+type TransferResult struct {
+	TxHash            common.Hash
+	Status            uint64 // types.ReceiptStatusSuccessful or types.ReceiptStatusFailed
+	BlockNumber       uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+
+	// Transfers are the token's Transfer logs of the transaction.
+	Transfers []*contractsgo.IERC20MetadataTransfer
+
+	// Balances are the ETH and token balances of the accounts involved
+	// before the transaction was sent and in its block; nil if they
+	// couldn't be read.
+	Balances *BalanceChanges
+}
+
+// Fee returns what the transaction cost in wei.
+func (r *TransferResult) Fee() *big.Int {
+	if r.EffectiveGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed))
+}
+
+// RevertError is returned for a transaction that was mined but reverted.
+// Its nonce is used and its fee paid, but it changed nothing. Reason is the
+// decoded revert, if replaying the transaction gave one.
+type RevertError struct {
+	TxHash      common.Hash
+	BlockNumber uint64
+	GasUsed     uint64
+	Reason      error
+}
+
+func (e *RevertError) Error() string {
+	msg := fmt.Sprintf("transaction %s reverted in block %d (gas used %d)", e.TxHash.Hex(), e.BlockNumber, e.GasUsed)
+	if e.Reason != nil {
+		msg += ": " + e.Reason.Error()
+	}
+	return msg
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Reason
+}
+
+// newRevertError describes a reverted transaction. Receipts don't hold revert
+// data, so the transaction is replayed as a call on the state of its block
+// to get the reason; transactions before it in the block can make that
+// differ, in which case Reason stays nil.
+func newRevertError(ctx context.Context, session *connection.Session, tx *types.Transaction, receipt *types.Receipt) *RevertError {
+	revertErr := &RevertError{TxHash: tx.Hash(), BlockNumber: receipt.BlockNumber.Uint64(), GasUsed: receipt.GasUsed}
+
+	// a broadcast transaction may have been signed by another account than the session
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		from = session.From
+	}
+	msg := ethereum.CallMsg{From: from, To: tx.To(), Gas: tx.Gas(), Value: tx.Value(), Data: tx.Data()}
+	_, err = session.Client.CallContract(ctx, msg, receipt.BlockNumber)
+	var reverted *reverts.Reverted
+	if errors.As(reverts.Wrap(err), &reverted) {
+		revertErr.Reason = reverted.Reason
+	}
+	return revertErr
+}
+
+// checkTransferred fails a successful transaction that moved no tokens: a
+// token that returns false instead of reverting emits no Transfer log.
+func (r *TransferResult) checkTransferred() error {
+	if r.Status == types.ReceiptStatusSuccessful && len(r.Transfers) == 0 {
+		return fmt.Errorf("transaction %s moved no tokens: %w", r.TxHash.Hex(), erc20.ErrReturnedFalse)
+	}
+	return nil
+}
+
+func (r *TransferResult) print() {
+	fmt.Printf("Block: %d, gas used: %d, effective gas price: %s wei\n", r.BlockNumber, r.GasUsed, r.EffectiveGasPrice)
+}
+
+// waitTransaction waits for tx to be mined and confirmed and prints its hash.
+// A reverted transaction returns its receipt with a *RevertError.
+func waitTransaction(ctx context.Context, session *connection.Session, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := bind.WaitMined(ctx, session.Client, tx)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("\nTransaction hash: 0x%x\n", tx.Hash())
+	if url := session.Profile.TxURL(tx.Hash()); url != "" {
+		fmt.Println("Explorer:", url)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, newRevertError(ctx, session, tx, receipt)
+	}
+
+	if err := session.WaitConfirmations(ctx, receipt.BlockNumber); err != nil {
+		return receipt, fmt.Errorf("failed waiting for confirmations of %s: %v", tx.Hash().Hex(), err)
+	}
+	return receipt, nil
+}
+
+// newTransferResult collects the outcome of tx from its receipt; Transfers
+// holds the Transfer logs of token.
+func newTransferResult(ctx context.Context, session *connection.Session, token *erc20.Token, tx *types.Transaction, receipt *types.Receipt) (*TransferResult, error) {
+	result := &TransferResult{
+		TxHash:            receipt.TxHash,
+		Status:            receipt.Status,
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+	}
+
+	// not every node fills in the price; work it out from the block's base fee
+	if result.EffectiveGasPrice == nil {
+		head, err := session.Client.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %v", result.BlockNumber, err)
+		}
+		result.EffectiveGasPrice = tx.GasPrice()
+		if head.BaseFee != nil && tx.Type() == types.DynamicFeeTxType {
+			result.EffectiveGasPrice = new(big.Int).Add(head.BaseFee, tx.EffectiveGasTipValue(head.BaseFee))
+		}
+	}
+
+	result.Transfers = token.Transfers(receipt)
+	return result, nil
+}
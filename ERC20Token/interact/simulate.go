@@ -0,0 +1,305 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// SimulatedTransfer is one transfer of a simulation. Err is set if the
+// transfer would revert; Gas is its estimate otherwise.
+type SimulatedTransfer struct {
+	To     common.Address
+	Amount units.Amount
+	Gas    uint64
+	Err    error
+}
+
+// SimulatedBatch is one disperse transaction of a batch simulation: the
+// recipients recipients[First:First+Count]. Err is set if it would revert;
+// Gas is its estimate otherwise, or 0 while Disperse isn't approved.
+type SimulatedBatch struct {
+	First int
+	Count int
+	Total units.Amount
+	Gas   uint64
+	Err   error
+}
+
+// ShortfallError is a simulated transfer the sender could no longer cover
+// after the transfers before it. The call itself runs on the latest state,
+// where it may well succeed, so this is what the simulation works out, not a
+// revert the token reported.
+type ShortfallError struct {
+	Sender  common.Address
+	Balance units.Amount // what the transfers before leave
+	Needed  units.Amount
+}
+
+func (e *ShortfallError) Error() string {
+	return fmt.Sprintf("%s would hold %s by then, needs %s", e.Sender.Hex(), e.Balance, e.Needed)
+}
+
+// Simulation is what a list of transfers would do if it were sent, one by
+// one or in batches.
+type Simulation struct {
+	Transfers []SimulatedTransfer
+	Batches   []SimulatedBatch
+	Reverts   int
+
+	// Approval is what Disperse would be approved for before the batches,
+	// if its allowance is short; zero otherwise. Until then the batches
+	// can't be estimated, and TotalGas holds the approval only.
+	Approval    units.Amount
+	ApprovalGas uint64
+
+	TotalGas    uint64
+	GasPrice    *big.Int // expected price per gas at the current base fee
+	MaxGasPrice *big.Int // the fee cap the transactions would be signed with
+	Cost        *big.Int // TotalGas at GasPrice, in wei
+	MaxCost     *big.Int // TotalGas at MaxGasPrice, in wei
+
+	BalanceBefore units.Amount
+	BalanceAfter  units.Amount // if every transfer that doesn't revert goes through
+}
+
+// SimulateTransfers runs transfers of amounts[i] to recipients[i] from the
+// session account through eth_call and gas estimation only; nothing is
+// signed or sent. Each call runs on the latest state, so the transfers
+// before it are accounted for by tracking the sender's balance: a transfer
+// it could no longer cover fails with a *ShortfallError.
+func SimulateTransfers(ctx context.Context, session *connection.Session, contractAddress string, recipients []common.Address, amounts []units.Amount) (*Simulation, error) {
+	if len(recipients) != len(amounts) {
+		return nil, fmt.Errorf("%d recipients but %d amounts", len(recipients), len(amounts))
+	}
+	if session.Signer == nil {
+		return nil, connection.ErrReadOnlySession
+	}
+
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	unit := token.Unit()
+	balance, err := token.BalanceOf(ctx, session.From)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{BalanceBefore: balance}
+	remaining := new(big.Int).Set(balance.Value)
+	for i, to := range recipients {
+		transfer := SimulatedTransfer{To: to, Amount: amounts[i]}
+		if remaining.Cmp(amounts[i].Value) < 0 {
+			transfer.Err = &ShortfallError{Sender: session.From, Balance: unit.Amount(new(big.Int).Set(remaining)), Needed: amounts[i]}
+		} else {
+			transfer.Gas, transfer.Err = simulateTransfer(ctx, session, token, to, amounts[i].Value)
+		}
+
+		if transfer.Err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sim.Reverts++
+		} else {
+			sim.TotalGas += transfer.Gas
+			// tokens sent to oneself stay where they are
+			if to != session.From {
+				remaining.Sub(remaining, amounts[i].Value)
+			}
+		}
+		sim.Transfers = append(sim.Transfers, transfer)
+	}
+	sim.BalanceAfter = unit.Amount(remaining)
+
+	if err := sim.price(ctx, session); err != nil {
+		return nil, err
+	}
+	return sim, nil
+}
+
+// SimulateBatch goes through the calls BatchTransfer would make, without
+// signing or sending anything: it checks the balance and the allowance of
+// Disperse at disperseAddress, and fits the recipients into batches by gas
+// estimate. If the allowance is short, the approval is estimated instead of
+// the batches, as the token won't let Disperse move anything before it. A
+// batch that would revert ends the simulation, as it ends BatchTransfer.
+func SimulateBatch(ctx context.Context, session *connection.Session, contractAddress, disperseAddress string, recipients []common.Address, amounts []units.Amount, opts BatchOptions) (*Simulation, error) {
+	if len(recipients) != len(amounts) {
+		return nil, fmt.Errorf("%d recipients but %d amounts", len(recipients), len(amounts))
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	if session.Signer == nil {
+		return nil, connection.ErrReadOnlySession
+	}
+	values := make([]*big.Int, len(amounts))
+	for i, amount := range amounts {
+		if amount.Value == nil || amount.Value.Sign() <= 0 {
+			return nil, fmt.Errorf("amount for %s must be positive", recipients[i].Hex())
+		}
+		values[i] = amount.Value
+	}
+
+	disperse := common.HexToAddress(disperseAddress)
+	token, err := OpenToken(ctx, session.Client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	unit := token.Unit()
+	balance, err := token.BalanceOf(ctx, session.From)
+	if err != nil {
+		return nil, err
+	}
+	total := unit.Amount(sum(values))
+
+	sim := &Simulation{BalanceBefore: balance, BalanceAfter: balance}
+	if balance.Value.Cmp(total.Value) < 0 {
+		sim.Batches = []SimulatedBatch{{Count: len(recipients), Total: total, Err: &ShortfallError{Sender: session.From, Balance: balance, Needed: total}}}
+		sim.Reverts = 1
+		return sim, sim.price(ctx, session)
+	}
+
+	if opts.MaxRecipients <= 0 {
+		opts.MaxRecipients = DefaultBatchRecipients
+	}
+	if opts.GasBudget == 0 {
+		head, err := session.Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block gas limit: %v", err)
+		}
+		opts.GasBudget = head.GasLimit / 2
+	}
+
+	allowance, err := Allowance(ctx, session.Client, contractAddress, session.From, disperse)
+	if err != nil {
+		return nil, err
+	}
+	if allowance.Value.Cmp(total.Value) < 0 {
+		sim.Approval = total
+		if err := token.Call(ctx, session.From, "approve", disperse, total.Value); err != nil {
+			return nil, fmt.Errorf("approval of %s would fail: %w", total, reverts.Wrap(err))
+		}
+		if sim.ApprovalGas, err = token.Estimate(ctx, session.From, "approve", disperse, total.Value); err != nil {
+			return nil, fmt.Errorf("failed to estimate gas of the approval: %w", reverts.Wrap(err))
+		}
+		sim.TotalGas = sim.ApprovalGas
+		for first := 0; first < len(recipients); first += opts.MaxRecipients {
+			count := min(opts.MaxRecipients, len(recipients)-first)
+			sim.Batches = append(sim.Batches, SimulatedBatch{First: first, Count: count, Total: unit.Amount(sum(values[first : first+count]))})
+		}
+		sim.BalanceAfter = unit.Amount(new(big.Int).Sub(balance.Value, total.Value))
+		return sim, sim.price(ctx, session)
+	}
+
+	remaining := new(big.Int).Set(balance.Value)
+	size := opts.MaxRecipients
+	for first := 0; first < len(recipients); {
+		count, gas, err := fitChunk(ctx, session, token.Address, disperse, recipients[first:], values[first:], size, opts.GasBudget)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sim.Batches = append(sim.Batches, SimulatedBatch{First: first, Count: len(recipients) - first, Total: unit.Amount(sum(values[first:])), Err: err})
+			sim.Reverts++
+			break
+		}
+		batch := SimulatedBatch{First: first, Count: count, Total: unit.Amount(sum(values[first : first+count])), Gas: gas}
+		sim.Batches = append(sim.Batches, batch)
+		sim.TotalGas += gas
+		for i := first; i < first+count; i++ {
+			if recipients[i] != session.From {
+				remaining.Sub(remaining, values[i])
+			}
+		}
+		size = count
+		first += count
+	}
+	sim.BalanceAfter = unit.Amount(remaining)
+	return sim, sim.price(ctx, session)
+}
+
+// simulateTransfer calls transfer(to, value) and estimates its gas. A
+// token that returns false fails with erc20.ErrReturnedFalse.
+func simulateTransfer(ctx context.Context, session *connection.Session, token *erc20.Token, to common.Address, value *big.Int) (uint64, error) {
+	if err := token.Call(ctx, session.From, "transfer", to, value); err != nil {
+		return 0, reverts.Wrap(err)
+	}
+
+	gas, err := token.Estimate(ctx, session.From, "transfer", to, value)
+	if err != nil {
+		return 0, reverts.Wrap(err)
+	}
+	return gas, nil
+}
+
+// price fills in the costs at the fees the session would pay now.
+func (sim *Simulation) price(ctx context.Context, session *connection.Session) error {
+	auth := &bind.TransactOpts{}
+	if err := session.Fees.Apply(ctx, auth); err != nil {
+		return err
+	}
+
+	if auth.GasPrice != nil {
+		sim.GasPrice, sim.MaxGasPrice = auth.GasPrice, auth.GasPrice
+	} else {
+		head, err := session.Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get latest header: %v", err)
+		}
+		sim.MaxGasPrice = auth.GasFeeCap
+		sim.GasPrice = new(big.Int).Add(head.BaseFee, auth.GasTipCap)
+		if sim.GasPrice.Cmp(sim.MaxGasPrice) > 0 {
+			sim.GasPrice = new(big.Int).Set(sim.MaxGasPrice)
+		}
+	}
+
+	gas := new(big.Int).SetUint64(sim.TotalGas)
+	sim.Cost = new(big.Int).Mul(gas, sim.GasPrice)
+	sim.MaxCost = new(big.Int).Mul(gas, sim.MaxGasPrice)
+	return nil
+}
+
+// Print reports the simulation: every transfer or batch that would revert,
+// then the totals.
+func (sim *Simulation) Print() {
+	for i, transfer := range sim.Transfers {
+		if transfer.Err != nil {
+			fmt.Printf("Transfer %d: %s to %s would revert: %v\n", i+1, transfer.Amount, transfer.To.Hex(), transfer.Err)
+		}
+	}
+	if sim.Approval.Value != nil {
+		fmt.Printf("Approval of Disperse for %s: %d gas\n", sim.Approval, sim.ApprovalGas)
+	}
+	for i, batch := range sim.Batches {
+		fmt.Printf("Batch %d: recipients %d-%d, %s", i+1, batch.First+1, batch.First+batch.Count, batch.Total)
+		switch {
+		case batch.Err != nil:
+			fmt.Printf(", would revert: %v", batch.Err)
+		case batch.Gas > 0:
+			fmt.Printf(", %d gas", batch.Gas)
+		}
+		fmt.Println()
+	}
+
+	if sim.Batches != nil {
+		fmt.Printf("Batches: %d, would revert: %d\n", len(sim.Batches), sim.Reverts)
+	} else {
+		fmt.Printf("Transfers: %d, would revert: %d\n", len(sim.Transfers), sim.Reverts)
+	}
+	if sim.Approval.Value != nil {
+		fmt.Println("The batches can only be estimated once Disperse is approved; the gas and cost below are the approval's")
+	}
+	fmt.Printf("Total gas: %d\n", sim.TotalGas)
+	fmt.Printf("ETH cost: %s (at most %s)\n", units.Ether.Format(sim.Cost), units.Ether.Format(sim.MaxCost))
+	fmt.Printf("Token balance: %s before, %s after\n", sim.BalanceBefore, sim.BalanceAfter)
+}
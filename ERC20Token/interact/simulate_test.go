@@ -0,0 +1,123 @@
+package interact
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+var simRecipients = []common.Address{
+	common.HexToAddress("0x1000000000000000000000000000000000000001"),
+	common.HexToAddress("0x2000000000000000000000000000000000000002"),
+	common.HexToAddress("0x3000000000000000000000000000000000000003"),
+}
+
+func deployDisperse(t *testing.T, session *connection.Session) string {
+	t.Helper()
+	auth, err := session.NextTransaction(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, _, err := contractsgo.DeployDisperse(auth, session.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address.Hex()
+}
+
+// A transfer to the sender itself leaves its balance as it is; a transfer
+// the transfers before it leave too little for fails with a ShortfallError.
+func TestSimulateTransfers(t *testing.T) {
+	ctx := context.Background()
+	session, token := newTokenChain(t)
+	unit, err := TokenUnit(ctx, session.Client, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim, err := SimulateTransfers(ctx, session, token,
+		[]common.Address{session.From, simRecipients[0], simRecipients[1]},
+		[]units.Amount{unit.Tokens(800), unit.Tokens(500), unit.Tokens(600)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, wantErr := range []bool{false, false, true} {
+		if got := sim.Transfers[i].Err != nil; got != wantErr {
+			t.Errorf("transfer %d: error is %v, want one: %v", i+1, sim.Transfers[i].Err, wantErr)
+		}
+	}
+	var shortfall *ShortfallError
+	if !errors.As(sim.Transfers[2].Err, &shortfall) || shortfall.Balance.Value.Cmp(unit.Tokens(500).Value) != 0 {
+		t.Errorf("error is %v, want a shortfall with 500 tokens left", sim.Transfers[2].Err)
+	}
+	if sim.Reverts != 1 || sim.BalanceAfter.Value.Cmp(unit.Tokens(500).Value) != 0 {
+		t.Errorf("%d reverts and %s after, want 1 and 500", sim.Reverts, sim.BalanceAfter)
+	}
+}
+
+func TestSimulateBatch(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name     string
+		approve  int64
+		amount   int64 // per recipient
+		batches  int
+		approval bool
+		reverts  int
+	}{
+		{"not approved", 0, 100, 2, true, 0},
+		{"approved", 300, 100, 2, false, 0},
+		{"short balance", 0, 400, 1, false, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			session, token := newTokenChain(t)
+			disperse := deployDisperse(t, session)
+			unit, err := TokenUnit(ctx, session.Client, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.approve > 0 {
+				if _, err := Approve(ctx, session, token, common.HexToAddress(disperse), unit.Tokens(test.approve)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			nonce, err := session.Client.PendingNonceAt(ctx, session.From)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			amounts := []units.Amount{unit.Tokens(test.amount), unit.Tokens(test.amount), unit.Tokens(test.amount)}
+			sim, err := SimulateBatch(ctx, session, token, disperse, simRecipients, amounts, BatchOptions{MaxRecipients: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sim.Batches) != test.batches || (sim.Approval.Value != nil) != test.approval || sim.Reverts != test.reverts {
+				t.Fatalf("%d batches, approval %s, %d reverts; want %d, %v, %d", len(sim.Batches), sim.Approval, sim.Reverts, test.batches, test.approval, test.reverts)
+			}
+			for i, batch := range sim.Batches {
+				switch {
+				case test.reverts > 0:
+					var shortfall *ShortfallError
+					if !errors.As(batch.Err, &shortfall) {
+						t.Errorf("batch %d: error is %v, want a shortfall", i+1, batch.Err)
+					}
+				case batch.Err != nil:
+					t.Errorf("batch %d: %v", i+1, batch.Err)
+				case test.approval && batch.Gas != 0, !test.approval && batch.Gas == 0:
+					t.Errorf("batch %d: gas is %d, want an estimate only once approved", i+1, batch.Gas)
+				}
+			}
+			if test.approval && (sim.ApprovalGas == 0 || sim.TotalGas != sim.ApprovalGas) {
+				t.Errorf("approval gas is %d of %d in total, want all of it", sim.ApprovalGas, sim.TotalGas)
+			}
+			if after, err := session.Client.PendingNonceAt(ctx, session.From); err != nil || after != nonce {
+				t.Errorf("nonce went from %d to %d (%v), want nothing sent", nonce, after, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package interact
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+// AccountBalance is what an account held at the block of a snapshot.
+type AccountBalance struct {
+	Label   string // what the account is to the caller, e.g. "Sender"; may be empty
+	Address common.Address
+	ETH     *big.Int
+	Token   units.Amount
+}
+
+// BalanceSnapshot holds the ETH and token balances of a set of accounts, all
+// read at the same block.
+type BalanceSnapshot struct {
+	Block    uint64
+	Balances []AccountBalance
+}
+
+// BalanceChange is how the balances of an account moved between two
+// snapshots; decreases are negative.
+type BalanceChange struct {
+	Label   string
+	Address common.Address
+	ETH     *big.Int
+	Token   units.Amount
+}
+
+// BalanceChanges are the balances of the accounts a transfer touched before
+// it was sent and in the block it was mined in. Other transactions mined in
+// between show up in them too.
+type BalanceChanges struct {
+	Before *BalanceSnapshot
+	After  *BalanceSnapshot
+}
+
+// TakeSnapshot reads the ETH and token balances of addresses at block, or
+// at the latest block if block is nil.
+func TakeSnapshot(ctx context.Context, client connection.Backend, contractAddress string, block *big.Int, addresses ...common.Address) (*BalanceSnapshot, error) {
+	token, err := OpenToken(ctx, client, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return takeSnapshot(ctx, client, token, block, addresses, nil)
+}
+
+// takeSnapshot reads the balances of addresses, labelled with labels if
+// given. With a nil block the latest block number is read first, so that all
+// balances come from the same block.
+func takeSnapshot(ctx context.Context, client connection.Backend, token *erc20.Token, block *big.Int, addresses []common.Address, labels []string) (*BalanceSnapshot, error) {
+	if block == nil {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %v", err)
+		}
+		block = new(big.Int).SetUint64(head)
+	}
+
+	snapshot := &BalanceSnapshot{Block: block.Uint64()}
+	for i, address := range addresses {
+		balance := AccountBalance{Address: address}
+		if i < len(labels) {
+			balance.Label = labels[i]
+		}
+
+		var err error
+		if balance.ETH, err = client.BalanceAt(ctx, address, block); err != nil {
+			return nil, fmt.Errorf("failed to get ETH balance of %s at block %d: %v", address.Hex(), snapshot.Block, err)
+		}
+		if balance.Token, err = token.BalanceAt(ctx, address, block); err != nil {
+			return nil, err
+		}
+		snapshot.Balances = append(snapshot.Balances, balance)
+	}
+	return snapshot, nil
+}
+
+// snapshotAccounts is takeSnapshot for the accounts of a transfer. Not
+// getting the balances doesn't fail the transfer, so errors are only logged
+// and give a nil snapshot.
+func snapshotAccounts(ctx context.Context, client connection.Backend, token *erc20.Token, block *big.Int, addresses []common.Address, labels []string) *BalanceSnapshot {
+	snapshot, err := takeSnapshot(ctx, client, token, block, addresses, labels)
+	if err != nil {
+		log.Printf("Failed to get balances: %v", err)
+		return nil
+	}
+	return snapshot
+}
+
+// balanceChanges snapshots the accounts of a transfer at the block it was
+// mined in and pairs that with before; nil if either snapshot is missing.
+func balanceChanges(ctx context.Context, client connection.Backend, token *erc20.Token, before *BalanceSnapshot, receipt *types.Receipt, addresses []common.Address, labels []string) *BalanceChanges {
+	if before == nil {
+		return nil
+	}
+	after := snapshotAccounts(ctx, client, token, receipt.BlockNumber, addresses, labels)
+	if after == nil {
+		return nil
+	}
+	return &BalanceChanges{Before: before, After: after}
+}
+
+// Diff returns how each account of s changed by the time of later. Accounts
+// missing from later are left out.
+func (s *BalanceSnapshot) Diff(later *BalanceSnapshot) []BalanceChange {
+	var changes []BalanceChange
+	for _, before := range s.Balances {
+		after, ok := later.balance(before.Address)
+		if !ok {
+			continue
+		}
+		changes = append(changes, BalanceChange{
+			Label:   before.Label,
+			Address: before.Address,
+			ETH:     new(big.Int).Sub(after.ETH, before.ETH),
+			Token:   before.Token.Unit.Amount(new(big.Int).Sub(after.Token.Value, before.Token.Value)),
+		})
+	}
+	return changes
+}
+
+func (s *BalanceSnapshot) balance(address common.Address) (AccountBalance, bool) {
+	for _, balance := range s.Balances {
+		if balance.Address == address {
+			return balance, true
+		}
+	}
+	return AccountBalance{}, false
+}
+
+// Changes returns the difference between Before and After.
+func (c *BalanceChanges) Changes() []BalanceChange {
+	return c.Before.Diff(c.After)
+}
+
+// Print shows the balances before and after, and what changed.
+func (c *BalanceChanges) Print() {
+	fmt.Printf("\nBefore Transfer (block %d):\n", c.Before.Block)
+	c.Before.Print()
+	fmt.Printf("After Transfer (block %d):\n", c.After.Block)
+	c.After.Print()
+	fmt.Println("Changes:")
+	for _, change := range c.Changes() {
+		fmt.Printf("%s: %s wei, %s\n", labelOf(change.Label), change.ETH, change.Token)
+	}
+	fmt.Println()
+}
+
+// Print shows every balance of the snapshot.
+func (s *BalanceSnapshot) Print() {
+	for _, balance := range s.Balances {
+		label := labelOf(balance.Label)
+		fmt.Printf("%s Address: %s\n", label, balance.Address.Hex())
+		fmt.Printf("%s ETH Balance: %s wei\n", label, balance.ETH)
+		fmt.Printf("%s Token Balance: %s\n\n", label, balance.Token)
+	}
+}
+
+func labelOf(label string) string {
+	if label == "" {
+		return "Account"
+	}
+	return label
+}
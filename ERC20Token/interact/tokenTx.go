@@ -3,137 +3,130 @@ package interact
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/big"
-	"sync"
 
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
-	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/erc20"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/registry"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
 
-	"github.com/defiweb/go-eth/abi"
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
-var (
-	testERC20Contract *contractsgo.TestERC20
-	testERC20Mu       sync.Mutex
-)
-
-func TransferTokens(contractAddress string, toAddress common.Address, value int64) (string, error) {
-	_, client, fromAddress, nonce, gasPrice, _ := connection.GetNextTransaction() //fromAddress is contract owner's address
+// TransferTokens sends value from the session account to toAddress and
+// waits until the transfer is mined and confirmed. A transfer that reverted
+// returns its result along with a *RevertError.
+func TransferTokens(ctx context.Context, session *connection.Session, contractAddress string, toAddress common.Address, value units.Amount) (*TransferResult, error) {
+	return transferTokens(ctx, session, contractAddress, toAddress, value, nil)
+}
 
-	fmt.Println("Transferring TestERC20 tokens...")
-	txHash, err := transferTokensWithGasEstimate(client, fromAddress, toAddress, nonce, gasPrice, value, contractAddress)
-	if err != nil {
-		return "", err
+// transferTokens is TransferTokens, handing the transaction to onSigned, if
+// set, as connection.OnSigned does.
+func transferTokens(ctx context.Context, session *connection.Session, contractAddress string, toAddress common.Address, value units.Amount, onSigned func(*types.Transaction) error) (*TransferResult, error) {
+	if err := session.CheckWritable(); err != nil {
+		return nil, err
 	}
-	return txHash.Hex(), nil
-}
 
-func GetClient() *ethclient.Client {
-	_, client, _, _, _, _ := connection.GetNextTransaction()
-	return client
+	fmt.Printf("Transferring %s...\n", value)
+	return transferTokensWithGasEstimate(ctx, session, toAddress, value, contractAddress, onSigned) //session.From is contract owner's address
 }
 
-func GetTestERC20Contract(client *ethclient.Client, contractAddress string) *contractsgo.TestERC20 {
-	testERC20, err := contractsgo.NewTestERC20(common.HexToAddress(contractAddress), client)
+// TokenUnit reads the symbol and decimals of the token at contractAddress.
+func TokenUnit(ctx context.Context, client bind.ContractBackend, contractAddress string) (units.Unit, error) {
+	token, err := OpenToken(ctx, client, contractAddress)
 	if err != nil {
-		log.Fatalf("Failed to instantiate TestERC20 contract: %v", err)
+		return units.Unit{}, err
 	}
-	return testERC20
+	return token.Unit(), nil
 }
 
-func transferTokensWithGasEstimate(client *ethclient.Client, fromAddress common.Address, toAddress common.Address, nonce uint64, gasPrice *big.Int, value int64, contractAddress string) (common.Hash, error) {
-	gasLimit, err := estimateGasForTransfer(client, fromAddress, toAddress, contractAddress, value)
-	if err != nil {
-		return common.Hash{}, err
+// OpenToken opens the ERC20 token at contractAddress.
+func OpenToken(ctx context.Context, client bind.ContractBackend, contractAddress string) (*erc20.Token, error) {
+	if !common.IsHexAddress(contractAddress) {
+		return nil, fmt.Errorf("invalid token address %q", contractAddress)
 	}
-	fmt.Println("Estimated gas:", gasLimit)
+	return erc20.Open(ctx, client, common.HexToAddress(contractAddress))
+}
 
-	auth, _, _, _, _, err := connection.GetNextTransaction()
-	if err != nil {
-		return common.Hash{}, err
+// TokenAddressFromConfig returns the token to work with on the chain: the
+// configured token, an address or the name of a deployment in the registry,
+// or else the deployment named registry.DefaultToken.
+func TokenAddressFromConfig(cfg *config.Config, chainID *big.Int) (common.Address, error) {
+	token := cfg.Token
+	if token == "" {
+		token = registry.DefaultToken
 	}
+	return registry.Resolve(cfg.Paths.DeploymentsFile, chainID, token)
+}
 
-	auth.From = fromAddress
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)
-	auth.GasLimit = gasLimit
-	auth.GasPrice = gasPrice
-
-	testERC20 := GetTestERC20Contract(client, contractAddress) //contractOwner consent, contract address
-	contractAddressObj := common.HexToAddress(contractAddress)
-
-	fmt.Println("\nBefore Transfer:")
-	printAddressDetails(client, testERC20, "Contract", contractAddressObj)
-	printAddressDetails(client, testERC20, "Sender", fromAddress)
-	printAddressDetails(client, testERC20, "Receiver", toAddress)
+func transferTokensWithGasEstimate(ctx context.Context, session *connection.Session, toAddress common.Address, value units.Amount, contractAddress string, onSigned func(*types.Transaction) error) (*TransferResult, error) {
+	client := session.Client
+	fromAddress := session.From
 
-	tx, err := testERC20.Transfer(auth, toAddress, big.NewInt(value)) //with contract owner consent and contract address, and toAddress, we now transfer tokens
+	token, err := OpenToken(ctx, client, contractAddress) //contractOwner consent, contract address
 	if err != nil {
-		return common.Hash{}, fmt.Errorf("failed to transfer tokens: %v", err)
+		return nil, err
 	}
 
-	_, err = bind.WaitMined(context.Background(), client, tx) //wait for the transaction to be mined
+	gasLimit, err := estimateGasForTransfer(ctx, token, fromAddress, toAddress, value.Value)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
-	fmt.Printf("\nTransaction hash: 0x%x\n", tx.Hash())
-
-	fmt.Println("\nAfter Transfer:")
-	printAddressDetails(client, testERC20, "Contract", contractAddressObj)
-	printAddressDetails(client, testERC20, "Sender", fromAddress)
-	printAddressDetails(client, testERC20, "Receiver", toAddress)
-
-	return tx.Hash(), nil
-}
+	fmt.Println("Estimated gas:", gasLimit)
 
-func estimateGasForTransfer(client *ethclient.Client, fromAddress common.Address, toAddress common.Address, contractAddress string, value int64) (uint64, error) {
-	store := abi.MustParseMethod("transfer(address,uint256)") //calling transfer function inside goeth abi for ERC20 contract
+	accounts := []common.Address{token.Address, fromAddress, toAddress}
+	labels := []string{"Contract", "Sender", "Receiver"}
+	before := snapshotAccounts(ctx, client, token, nil, accounts, labels)
 
-	abiData, err := store.EncodeArgs(toAddress, big.NewInt(value))
+	auth, err := session.NextTransaction(ctx)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	toContractAddress := common.HexToAddress(contractAddress)
-
-	callMsg := ethereum.CallMsg{
-		From:     fromAddress,
-		To:       &toContractAddress,	//this line always takes contract address
-		GasPrice: nil,
-		Value:    big.NewInt(0),
-		Data:     abiData,
+	auth.GasLimit = gasLimit
+	if onSigned != nil {
+		connection.OnSigned(auth, onSigned)
 	}
 
-	return client.EstimateGas(context.Background(), callMsg)
-}
+	tx, err := token.Transfer(auth, toAddress, value.Value) //with contract owner consent and contract address, and toAddress, we now transfer tokens
+	if err != nil {
+		session.TransactionFailed(auth, err)
+		return nil, fmt.Errorf("failed to transfer tokens: %w", reverts.Wrap(err))
+	}
 
-func GetBalance(testERC20 *contractsgo.TestERC20, address common.Address) *big.Int {
-	balance, err := testERC20.BalanceOf(&bind.CallOpts{}, address)
+	receipt, err := waitTransaction(ctx, session, tx) //wait for the transaction to be mined
+	if receipt == nil {
+		return nil, err
+	}
+	result, resultErr := newTransferResult(ctx, session, token, tx, receipt)
+	if resultErr != nil {
+		return nil, resultErr
+	}
+	result.Balances = balanceChanges(ctx, client, token, before, receipt, accounts, labels)
+	result.print()
 	if err != nil {
-		log.Fatal(err)
+		return result, err
 	}
-	return balance
+	if err := result.checkTransferred(); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
-func printAddressDetails(client *ethclient.Client, testERC20 *contractsgo.TestERC20, label string, address common.Address) {
-	ethBalance, err := client.BalanceAt(context.Background(), address, nil)
-	if err != nil {
-		log.Printf("Failed to get ETH balance for %s: %v", label, err)
-		return
+// estimateGasForTransfer calls transfer first, so that a token that would
+// return false is caught here rather than after paying for it, then
+// estimates the gas of the transfer.
+func estimateGasForTransfer(ctx context.Context, token *erc20.Token, fromAddress common.Address, toAddress common.Address, value *big.Int) (uint64, error) {
+	if err := token.Call(ctx, fromAddress, "transfer", toAddress, value); err != nil {
+		return 0, fmt.Errorf("transfer would fail: %w", reverts.Wrap(err))
 	}
 
-	tokenBalance, err := testERC20.BalanceOf(&bind.CallOpts{}, address)
+	gas, err := token.Estimate(ctx, fromAddress, "transfer", toAddress, value)
 	if err != nil {
-		log.Printf("Failed to get token balance for %s: %v", label, err)
-		return
+		return 0, fmt.Errorf("failed to estimate gas: %w", reverts.Wrap(err))
 	}
-
-	fmt.Printf("%s Address: %s\n", label, address.Hex())
-	fmt.Printf("%s ETH Balance: %s wei\n", label, ethBalance.String())
-	fmt.Printf("%s Token Balance: %s\n\n", label, tokenBalance.String())
+	return gas, nil
 }
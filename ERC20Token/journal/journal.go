@@ -0,0 +1,202 @@
+// Package journal records what every transfer of an airdrop was meant to do
+// and which transaction did it, so that a run that is repeated after a crash
+// or a failed attempt doesn't pay the same line item twice.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofrs/flock"
+)
+
+// Key identifies one transfer: a line item of a campaign and its recipient.
+type Key struct {
+	Campaign  string
+	Line      int
+	Recipient common.Address
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s:%d:%s", k.Campaign, k.Line, k.Recipient.Hex())
+}
+
+// State is how far a transfer got.
+type State string
+
+const (
+	// Signed transfers have a transaction that may or may not have been
+	// sent; only the chain can tell.
+	Signed State = "signed"
+	// Transferred transfers were mined and moved the tokens.
+	Transferred State = "transferred"
+	// Failed transfers were not sent, or were mined without moving the
+	// tokens, and can be tried again.
+	Failed State = "failed"
+)
+
+// Chain identifies the chain an entry was made on. Development chains such
+// as Ganache reuse their chain ID every time they start afresh, but not their
+// genesis block, so both are recorded.
+type Chain struct {
+	ID      uint64      `json:"chain_id"`
+	Genesis common.Hash `json:"genesis"`
+}
+
+// Entry is the latest record of a transfer.
+type Entry struct {
+	Key string `json:"key"`
+	Chain
+	State  State          `json:"state"`
+	Token  common.Address `json:"token"`
+	From   common.Address `json:"from"`
+	Amount *big.Int       `json:"amount"` // base units
+	TxHash common.Hash    `json:"tx_hash,omitempty"`
+	Nonce  uint64         `json:"nonce,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Time   time.Time      `json:"time"`
+}
+
+// Journal is an append-only file of JSON entries, one per line; the last
+// entry of a key is its current state. Only one process can have a journal
+// open at a time.
+type Journal struct {
+	path  string
+	chain Chain
+	lock  *flock.Flock
+
+	mu        sync.Mutex
+	file      *os.File
+	entries   map[string]Entry
+	size      int64 // of the lines loaded, without a cut-off last one
+	truncated bool
+}
+
+// Open opens (or creates) the journal at path for chain. Entries made on
+// other chains stay in the file but are left out, as if they weren't there.
+func Open(path string, chain Chain) (*Journal, error) {
+	lock := flock.New(path + ".lock")
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock journal: %v", err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("journal %s is in use by another process", path)
+	}
+
+	j := &Journal{path: path, chain: chain, lock: lock, entries: make(map[string]Entry)}
+	if err := j.load(); err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	if j.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644); err != nil {
+		lock.Unlock()
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+	// drop a cut-off last line, or entries after it would follow a line
+	// that doesn't load
+	if err := j.file.Truncate(j.size); err != nil {
+		j.Close()
+		return nil, fmt.Errorf("failed to truncate journal: %v", err)
+	}
+	// a complete last line may still lack its newline
+	if j.truncated {
+		if _, err := j.file.Write([]byte("\n")); err != nil {
+			j.Close()
+			return nil, fmt.Errorf("failed to write journal: %v", err)
+		}
+	}
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %v", err)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	j.size = int64(len(data))
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += len(line) + 1
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// a crash can cut the last line short; it was never synced, so
+			// the transfer it records never got further
+			if i == len(lines)-1 {
+				j.size = int64(start)
+				break
+			}
+			return fmt.Errorf("%s:%d: %v", j.path, i+1, err)
+		}
+		if entry.Chain != j.chain {
+			continue
+		}
+		j.entries[entry.Key] = entry
+	}
+	j.truncated = j.size > 0 && data[j.size-1] != '\n'
+	return nil
+}
+
+// Lookup returns the latest entry of key on the journal's chain.
+func (j *Journal) Lookup(key Key) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.entries[key.String()]
+	return entry, ok
+}
+
+// Record appends entry and syncs it to disk before returning, so that it
+// survives a crash right after.
+func (j *Journal) Record(key Key, entry Entry) error {
+	entry.Key, entry.Chain = key.String(), j.chain
+	entry.Time = time.Now().UTC()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %v", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %v", err)
+	}
+	j.entries[entry.Key] = entry
+	return nil
+}
+
+// Entries returns the latest entry of every key on the journal's chain.
+func (j *Journal) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]Entry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Close closes the journal and lets other processes open it.
+func (j *Journal) Close() error {
+	err := j.file.Close()
+	j.lock.Unlock()
+	return err
+}
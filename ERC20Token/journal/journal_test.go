@@ -0,0 +1,202 @@
+package journal
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	token = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	from  = common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	ganache = Chain{ID: 1337, Genesis: common.HexToHash("0x01")}
+)
+
+func key(line int) Key {
+	return Key{Campaign: "spring", Line: line, Recipient: common.BigToAddress(big.NewInt(int64(line)))}
+}
+
+func open(t *testing.T, path string, chain Chain) *Journal {
+	t.Helper()
+	j, err := Open(path, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func record(t *testing.T, j *Journal, k Key, state State, nonce uint64) {
+	t.Helper()
+	entry := Entry{State: state, Token: token, From: from, Amount: big.NewInt(100), TxHash: common.BigToHash(big.NewInt(int64(nonce))), Nonce: nonce}
+	if err := j.Record(k, entry); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJournalLatestEntryWinsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := open(t, path, ganache)
+	record(t, j, key(1), Signed, 7)
+	record(t, j, key(2), Failed, 0)
+	record(t, j, key(1), Transferred, 7)
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j = open(t, path, ganache)
+	defer j.Close()
+	for _, test := range []struct {
+		key   Key
+		state State
+	}{
+		{key(1), Transferred},
+		{key(2), Failed},
+	} {
+		entry, ok := j.Lookup(test.key)
+		if !ok {
+			t.Fatalf("%s is missing", test.key)
+		}
+		if entry.State != test.state || entry.Amount.Cmp(big.NewInt(100)) != 0 || entry.Token != token {
+			t.Errorf("%s is %+v, want state %s", test.key, entry, test.state)
+		}
+	}
+	if _, ok := j.Lookup(key(3)); ok {
+		t.Error("a key never recorded was found")
+	}
+	if n := len(j.Entries()); n != 2 {
+		t.Errorf("%d entries, want 2", n)
+	}
+}
+
+func TestJournalLoad(t *testing.T) {
+	complete := `{"key":"spring:1:0x0000000000000000000000000000000000000001","chain_id":1337,"genesis":"0x0000000000000000000000000000000000000000000000000000000000000001","state":"signed","token":"0x1000000000000000000000000000000000000001","from":"0x2000000000000000000000000000000000000002","amount":100,"tx_hash":"0x0000000000000000000000000000000000000000000000000000000000000007","nonce":7,"time":"2026-01-02T03:04:05Z"}`
+	for _, test := range []struct {
+		name    string
+		data    string
+		state   State  // of key(1); empty if it isn't there
+		errText string // if loading fails
+	}{
+		{"empty", "", "", ""},
+		{"complete", complete + "\n", Signed, ""},
+		{"blank lines", "\n" + complete + "\n\n", Signed, ""},
+		{"truncated last line", complete + "\n" + complete[:40], Signed, ""},
+		{"truncated only line", complete[:40], "", ""},
+		{"no newline at the end", complete, Signed, ""},
+		{"other chain", strings.Replace(complete, `"chain_id":1337`, `"chain_id":1`, 1) + "\n", "", ""},
+		{"restarted chain", strings.Replace(complete, `01","state"`, `02","state"`, 1) + "\n", "", ""},
+		{"corrupt line before the last", complete[:40] + "\n" + complete + "\n", "", "journal.jsonl:1"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			if err := os.WriteFile(path, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			j, err := Open(path, ganache)
+			if test.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errText) {
+					t.Fatalf("error is %v, want one containing %q", err, test.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer j.Close()
+			entry, ok := j.Lookup(key(1))
+			if test.state == "" {
+				if ok {
+					t.Fatalf("found %+v, want nothing", entry)
+				}
+				return
+			}
+			if !ok || entry.State != test.state || entry.Nonce != 7 {
+				t.Fatalf("entry is %+v (found %v), want state %s nonce 7", entry, ok, test.state)
+			}
+		})
+	}
+}
+
+// A cut-off line is dropped on opening, so entries recorded after it don't
+// leave it in the middle of the journal, where it would no longer load.
+func TestJournalRecordsAfterTruncatedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := open(t, path, ganache)
+	record(t, j, key(1), Transferred, 1)
+	j.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"key":"spring:2:0x00000000000000000000000000000000000`)
+	f.Close()
+
+	j = open(t, path, ganache)
+	record(t, j, key(2), Signed, 2)
+	j.Close()
+
+	j = open(t, path, ganache)
+	defer j.Close()
+	for _, k := range []Key{key(1), key(2)} {
+		if _, ok := j.Lookup(k); !ok {
+			t.Errorf("%s is missing after reopening", k)
+		}
+	}
+}
+
+// The same campaign run on another chain, or on a development chain started
+// afresh with the same chain ID, starts from nothing, and neither run changes
+// what the other recorded.
+func TestJournalKeepsChainsApart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	restarted := Chain{ID: ganache.ID, Genesis: common.HexToHash("0x02")}
+	sepolia := Chain{ID: 11155111, Genesis: ganache.Genesis}
+
+	j := open(t, path, ganache)
+	record(t, j, key(1), Transferred, 1)
+	j.Close()
+
+	for _, chain := range []Chain{restarted, sepolia} {
+		j = open(t, path, chain)
+		if entry, ok := j.Lookup(key(1)); ok {
+			t.Fatalf("chain %+v has the entry %+v of another chain", chain, entry)
+		}
+		if n := len(j.Entries()); n != 0 {
+			t.Fatalf("chain %+v has %d entries, want none", chain, n)
+		}
+		record(t, j, key(1), Signed, 2)
+		j.Close()
+	}
+
+	for _, test := range []struct {
+		chain Chain
+		state State
+	}{
+		{ganache, Transferred},
+		{restarted, Signed},
+		{sepolia, Signed},
+	} {
+		j = open(t, path, test.chain)
+		entry, ok := j.Lookup(key(1))
+		j.Close()
+		if !ok || entry.State != test.state || entry.Chain != test.chain {
+			t.Errorf("chain %+v: entry is %+v (found %v), want state %s", test.chain, entry, ok, test.state)
+		}
+	}
+}
+
+func TestJournalIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := open(t, path, ganache)
+
+	if _, err := Open(path, ganache); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("second Open gave %v, want the journal in use", err)
+	}
+	j.Close()
+	open(t, path, ganache).Close()
+}
@@ -1,9 +1,39 @@
 package main
 
 import (
-	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/deploy"
+	"log"
+	"os"
 )
 
 func main() {
-	deploy.RunTestERC20Contract()
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+	switch command {
+	case "wallet":
+		err = runWallet(os.Args[2:])
+	case "allowance":
+		err = runAllowance(os.Args[2:])
+	case "token":
+		err = runToken(os.Args[2:])
+	case "airdrop":
+		err = runAirdrop(os.Args[2:])
+	case "offline":
+		err = runOffline(os.Args[2:])
+	case "deployments":
+		err = runDeployments(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "demo":
+		err = runDemo(os.Args[2:])
+	default:
+		// anything else is the flags of a deployment
+		err = runDeploy(os.Args[1:])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gofrs/flock"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+const offlineUsage = "usage: ERC20Token offline params|sign|broadcast [flags]"
+
+// runOffline splits an airdrop between an online machine and an air-gapped
+// one holding the key: params reads what signing needs from the chain, sign
+// signs the transfers without a node, broadcast sends them.
+func runOffline(args []string) error {
+	if len(args) == 0 {
+		return errors.New(offlineUsage)
+	}
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("offline "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "params":
+		from := fs.String("from", "", "account that will sign the transfers")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		fromAddr, err := parseAddress("from", *from)
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		address, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		token, err := interact.OpenToken(ctx, session.Client, address)
+		if err != nil {
+			return err
+		}
+		nonce, err := session.Client.PendingNonceAt(ctx, fromAddr)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce: %v", err)
+		}
+		auth := &bind.TransactOpts{}
+		if err := session.Fees.Apply(ctx, auth); err != nil {
+			return err
+		}
+
+		// the fees are what the session would pay now; leave room for them to rise
+		gwei := units.Unit{Decimals: 9}
+		feeFlags := fmt.Sprintf("-max-fee-gwei %s -priority-fee-gwei %s", gwei.Format(auth.GasFeeCap), gwei.Format(auth.GasTipCap))
+		if auth.GasPrice != nil {
+			feeFlags = fmt.Sprintf("-legacy-tx true -max-fee-gwei %s", gwei.Format(auth.GasPrice))
+		}
+		fmt.Printf("Chain ID: %s\nNonce: %d\nToken: %s (%s, %d decimals)\n", session.ChainID, nonce, token.Address.Hex(), token.Symbol, token.Decimals)
+		fmt.Printf("Sign with: -token %s -chain-id %s -nonce %d -decimals %d -symbol %q %s\n", token.Address.Hex(), session.ChainID, nonce, token.Decimals, token.Symbol, feeFlags)
+		return nil
+
+	case "sign":
+		file := fs.String("file", "", "CSV file of address,amount lines; amounts in tokens")
+		out := fs.String("out", "signed.txt", "file to write the signed transactions to")
+		chainID := fs.Int64("chain-id", 0, "chain the transactions are for")
+		nonce := fs.Int64("nonce", -1, "nonce of the first transfer")
+		gas := fs.Uint64("gas", 100000, "gas limit of every transfer")
+		decimals := fs.Int("decimals", -1, "decimals of the token")
+		symbol := fs.String("symbol", "", "symbol of the token, if amounts in the file carry it")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if *file == "" {
+			return errors.New("-file is required")
+		}
+		if *chainID <= 0 || *nonce < 0 || *decimals < 0 || *decimals > 255 {
+			return errors.New("-chain-id, -nonce and -decimals are required; get them with offline params")
+		}
+		token, err := interact.TokenAddressFromConfig(cfg, big.NewInt(*chainID))
+		if err != nil {
+			return err
+		}
+		params, err := offlineParams(cfg.Fees)
+		if err != nil {
+			return err
+		}
+		params.ChainID, params.Nonce, params.GasLimit = big.NewInt(*chainID), uint64(*nonce), *gas
+
+		recipients, amounts, _, err := readAirdropFile(*file, units.Unit{Symbol: *symbol, Decimals: uint8(*decimals)})
+		if err != nil {
+			return err
+		}
+		signer, err := connection.NewSigner(ctx, cfg.Signer)
+		if err != nil {
+			return err
+		}
+		signed, err := interact.SignTransfers(ctx, signer, token, params, recipients, amounts)
+		if err != nil {
+			return err
+		}
+		if err := interact.WriteSignedTransfers(*out, signed); err != nil {
+			return err
+		}
+		fmt.Printf("Signed %d transfers from %s, nonces %d to %d, into %s\n", len(signed), signer.Address().Hex(), params.Nonce, params.Nonce+uint64(len(signed))-1, *out)
+		return nil
+
+	case "broadcast":
+		file := fs.String("file", "signed.txt", "file of signed transactions from offline sign")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		txs, err := interact.ReadSignedTransactions(*file)
+		if err != nil {
+			return err
+		}
+
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		// hand each transaction to the tracker once it is sent, so an
+		// interrupted broadcast doesn't lose the ones it was waiting for
+		results, err := interact.Broadcast(ctx, session, txs, func(tx *types.Transaction) error {
+			return appendHash(cfg.Paths.HashFile, tx.Hash().Hex())
+		})
+
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Printf("Transaction %s (nonce %d) failed: %v\n", result.TxHash.Hex(), result.Nonce, result.Err)
+				failed++
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d transactions failed", failed, len(txs))
+		}
+		fmt.Printf("Broadcast %d transactions; their hashes are in %s\n", len(txs), cfg.Paths.HashFile)
+		return nil
+
+	default:
+		return errors.New(offlineUsage)
+	}
+}
+
+// offlineParams takes the fees from the configuration, which offline has
+// to pin: max-fee-gwei and priority-fee-gwei, or max-fee-gwei alone as the
+// gas price of legacy transactions.
+func offlineParams(fees config.Fees) (interact.OfflineParams, error) {
+	opts, err := connection.FeeOptionsFromConfig(fees)
+	if err != nil {
+		return interact.OfflineParams{}, err
+	}
+	if opts.MaxFeeCap == nil {
+		return interact.OfflineParams{}, errors.New("offline signing needs -max-fee-gwei")
+	}
+	if opts.Legacy {
+		return interact.OfflineParams{GasPrice: opts.MaxFeeCap}, nil
+	}
+	if opts.TipCap == nil {
+		return interact.OfflineParams{}, errors.New("offline signing needs -priority-fee-gwei, or -legacy-tx")
+	}
+	return interact.OfflineParams{GasFeeCap: opts.MaxFeeCap, GasTipCap: opts.TipCap}, nil
+}
+
+// appendHash adds a transaction hash to the tracker's hash file, locked
+// like the generator locks it. A hash the file already has, such as one sent
+// again by a rerun while still pending, isn't added twice.
+func appendHash(hashFile, txHash string) error {
+	lock := flock.New(hashFile)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("error acquiring file lock: %v", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(hashFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading hash file: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == txHash {
+			return nil
+		}
+	}
+
+	file, err := os.OpenFile(hashFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening hash file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(txHash + "\n"); err != nil {
+		return fmt.Errorf("error writing to hash file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package registry records the contracts deployed on each chain in a JSON
+// file, by chain ID and a name per deployment, so that commands find them
+// by name instead of by address.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gofrs/flock"
+)
+
+const (
+	// DefaultToken names the token commands work with if none is set.
+	DefaultToken = "token"
+	// DefaultDisperse names the Disperse contract batch airdrops use.
+	DefaultDisperse = "disperse"
+)
+
+// ErrNameTaken is returned by Record for a name that is recorded on the
+// chain for a contract at another address.
+var ErrNameTaken = errors.New("deployment name is taken")
+
+// Arg is a constructor argument of a deployment, its value as text.
+type Arg struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Deployment is a contract deployed from one of the build artifacts.
+// ABIHash and BytecodeHash are the keccak256 hashes of its .abi file and of
+// its creation bytecode without the constructor arguments; EncodedArgs are
+// the arguments as the constructor got them. A contract deployed with
+// CREATE2 has the Factory and Salt; if it was found already deployed, its
+// transaction may be unknown and TxHash and Block zero.
+type Deployment struct {
+	Contract     string          `json:"contract"`
+	Address      common.Address  `json:"address"`
+	TxHash       common.Hash     `json:"tx_hash"`
+	Block        uint64          `json:"block"`
+	Deployer     common.Address  `json:"deployer"`
+	Args         []Arg           `json:"constructor_args"`
+	EncodedArgs  hexutil.Bytes   `json:"encoded_args,omitempty"`
+	Factory      *common.Address `json:"factory,omitempty"`
+	Salt         *common.Hash    `json:"salt,omitempty"`
+	ABIHash      common.Hash     `json:"abi_hash"`
+	BytecodeHash common.Hash     `json:"bytecode_hash"`
+	Time         time.Time       `json:"time"`
+}
+
+// Registry holds the deployments of every chain, by decimal chain ID and
+// name.
+type Registry struct {
+	Chains map[string]map[string]Deployment
+}
+
+// Load reads the registry at path; a missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{Chains: make(map[string]map[string]Deployment)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployments: %v", err)
+	}
+	if err := json.Unmarshal(data, &r.Chains); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return r, nil
+}
+
+// Lookup returns the deployment named name on the chain.
+func (r *Registry) Lookup(chainID *big.Int, name string) (Deployment, bool) {
+	d, ok := r.Chains[chainID.String()][name]
+	return d, ok
+}
+
+// Find returns a deployment on the chain at address, and its name.
+func (r *Registry) Find(chainID *big.Int, address common.Address) (string, Deployment, bool) {
+	for _, name := range r.Names(chainID) {
+		if d := r.Chains[chainID.String()][name]; d.Address == address {
+			return name, d, true
+		}
+	}
+	return "", Deployment{}, false
+}
+
+// Names returns the names of the deployments on the chain, sorted.
+func (r *Registry) Names(chainID *big.Int) []string {
+	var names []string
+	for name := range r.Chains[chainID.String()] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Record adds d to the registry at path as name on the chain and returns
+// the deployment of that name it replaced, if any. A name recorded for
+// another address is only replaced if replace is set; otherwise Record
+// fails with ErrNameTaken. Recording the same address again, as a CREATE2
+// deployment found already deployed is, only updates the entry. A d whose
+// transaction is unknown takes it from a deployment recorded at its address.
+// The file is locked while it is updated and replaced in one rename, so it
+// is never seen half written.
+func Record(path string, chainID *big.Int, name string, d Deployment, replace bool) (*Deployment, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("failed to lock deployments: %v", err)
+	}
+	defer lock.Unlock()
+
+	r, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	chain := chainID.String()
+	if r.Chains[chain] == nil {
+		r.Chains[chain] = make(map[string]Deployment)
+	}
+	var replaced *Deployment
+	if previous, ok := r.Chains[chain][name]; ok {
+		if previous.Address != d.Address && !replace {
+			return nil, fmt.Errorf("%w: %q on chain %s is the %s at %s", ErrNameTaken, name, chain, previous.Contract, previous.Address.Hex())
+		}
+		replaced = &previous
+	}
+	if d.TxHash == (common.Hash{}) {
+		if _, known, ok := r.Find(chainID, d.Address); ok && known.Contract == d.Contract {
+			d.TxHash, d.Block, d.Deployer = known.TxHash, known.Block, known.Deployer
+		}
+	}
+	r.Chains[chain][name] = d
+
+	data, err := json.MarshalIndent(r.Chains, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write deployments: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write deployments: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write deployments: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write deployments: %v", err)
+	}
+	return replaced, nil
+}
+
+// Resolve returns the contract ref stands for on the chain: ref itself if it
+// is an address, otherwise the deployment of that name in the registry at
+// path.
+func Resolve(path string, chainID *big.Int, ref string) (common.Address, error) {
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	r, err := Load(path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	d, ok := r.Lookup(chainID, ref)
+	if !ok {
+		return common.Address{}, fmt.Errorf("no deployment named %q on chain %s in %s", ref, chainID, path)
+	}
+	return d.Address, nil
+}
+
+// CheckName returns an error if name can't name a deployment: it must be
+// letters, digits, '-', '_' and '.', and not look like an address.
+func CheckName(name string) error {
+	if name == "" {
+		return errors.New("deployment name is empty")
+	}
+	if strings.HasPrefix(name, "0x") {
+		return fmt.Errorf("deployment name %q starts like an address", name)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return fmt.Errorf("deployment name %q has a character other than letters, digits, '-', '_' and '.'", name)
+		}
+	}
+	return nil
+}
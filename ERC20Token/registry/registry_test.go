@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	chainID = big.NewInt(1337)
+	first   = Deployment{Contract: "TestERC20", Address: common.HexToAddress("0x1000000000000000000000000000000000000001"), TxHash: common.HexToHash("0x01"), Block: 1}
+	second  = Deployment{Contract: "TestERC20", Address: common.HexToAddress("0x2000000000000000000000000000000000000002"), TxHash: common.HexToHash("0x02"), Block: 2}
+)
+
+func TestRecordResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	if _, err := Record(path, chainID, "token", first, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		chainID *big.Int
+		ref     string
+		want    common.Address
+		errText string
+	}{
+		{chainID, "token", first.Address, ""},
+		{chainID, second.Address.Hex(), second.Address, ""},
+		{chainID, "disperse", common.Address{}, `no deployment named "disperse" on chain 1337`},
+		{big.NewInt(1), "token", common.Address{}, `no deployment named "token" on chain 1`},
+	} {
+		got, err := Resolve(path, test.chainID, test.ref)
+		if test.errText == "" && err != nil || test.errText != "" && (err == nil || !strings.Contains(err.Error(), test.errText)) {
+			t.Errorf("%s on chain %s: error is %v, want %q", test.ref, test.chainID, err, test.errText)
+		}
+		if got != test.want {
+			t.Errorf("%s on chain %s is %s, want %s", test.ref, test.chainID, got.Hex(), test.want.Hex())
+		}
+	}
+}
+
+func TestRecordTakenName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	if _, err := Record(path, chainID, "token", first, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// another contract under the name is refused, and the entry kept
+	if _, err := Record(path, chainID, "token", second, false); !errors.Is(err, ErrNameTaken) {
+		t.Fatalf("error is %v, want ErrNameTaken", err)
+	}
+	if got, err := Resolve(path, chainID, "token"); err != nil || got != first.Address {
+		t.Fatalf("token is %s (%v) after a refused record, want %s", got.Hex(), err, first.Address.Hex())
+	}
+
+	// the same address again, found deployed without its transaction, keeps
+	// the transaction recorded for it
+	again := first
+	again.TxHash, again.Block = common.Hash{}, 0
+	if _, err := Record(path, chainID, "token", again, false); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := r.Lookup(chainID, "token"); d.TxHash != first.TxHash || d.Block != first.Block {
+		t.Fatalf("token was deployed in %s at %d, want %s at %d", d.TxHash.Hex(), d.Block, first.TxHash.Hex(), first.Block)
+	}
+
+	// replacing it on purpose returns the one it replaced
+	replaced, err := Record(path, chainID, "token", second, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced == nil || replaced.Address != first.Address {
+		t.Fatalf("replaced %v, want the deployment at %s", replaced, first.Address.Hex())
+	}
+	if got, err := Resolve(path, chainID, "token"); err != nil || got != second.Address {
+		t.Fatalf("token is %s (%v), want %s", got.Hex(), err, second.Address.Hex())
+	}
+}
+
+// Records made at the same time each take the lock, read what the others
+// wrote and replace the file whole, so none is lost and no temporary file
+// is left behind.
+func TestRecordConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployments.json")
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := first
+			d.Address = common.BigToAddress(big.NewInt(int64(i + 1)))
+			_, errs[i] = Record(path, chainID, fmt.Sprintf("token-%d", i), d, false)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := r.Names(chainID); len(names) != n {
+		t.Fatalf("%d deployments recorded, want %d: %v", len(names), n, names)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "deployments.json" && name != "deployments.json.lock" {
+			t.Errorf("%s is left in the directory", name)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		errText string // empty if valid
+	}{
+		{"token", ""},
+		{"usdc-v2.1_test", ""},
+		{"", "deployment name is empty"},
+		{"0xtoken", "starts like an address"},
+		{"my token", "has a character other than"},
+		{"token/1", "has a character other than"},
+	} {
+		err := CheckName(test.name)
+		if test.errText == "" && err != nil || test.errText != "" && (err == nil || !strings.Contains(err.Error(), test.errText)) {
+			t.Errorf("%q: error is %v, want %q", test.name, err, test.errText)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package reverts
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The custom errors of OpenZeppelin's ERC20 (IERC20Errors).
+
+// ERC20InsufficientBalance reverts a transfer of more than Sender holds.
+type ERC20InsufficientBalance struct {
+	Sender  common.Address
+	Balance *big.Int
+	Needed  *big.Int
+}
+
+func (e *ERC20InsufficientBalance) Error() string {
+	return fmt.Sprintf("ERC20InsufficientBalance: %s holds %s, needs %s", e.Sender.Hex(), e.Balance, e.Needed)
+}
+
+// ERC20InvalidSender reverts a transfer from an address that can't send,
+// such as the zero address.
+type ERC20InvalidSender struct {
+	Sender common.Address
+}
+
+func (e *ERC20InvalidSender) Error() string {
+	return fmt.Sprintf("ERC20InvalidSender: %s", e.Sender.Hex())
+}
+
+// ERC20InvalidReceiver reverts a transfer to an address that can't receive,
+// such as the zero address.
+type ERC20InvalidReceiver struct {
+	Receiver common.Address
+}
+
+func (e *ERC20InvalidReceiver) Error() string {
+	return fmt.Sprintf("ERC20InvalidReceiver: %s", e.Receiver.Hex())
+}
+
+// ERC20InsufficientAllowance reverts a transferFrom of more than Spender
+// was allowed.
+type ERC20InsufficientAllowance struct {
+	Spender   common.Address
+	Allowance *big.Int
+	Needed    *big.Int
+}
+
+func (e *ERC20InsufficientAllowance) Error() string {
+	return fmt.Sprintf("ERC20InsufficientAllowance: %s may spend %s, needs %s", e.Spender.Hex(), e.Allowance, e.Needed)
+}
+
+// ERC20InvalidApprover reverts an approval from an address that can't
+// approve, such as the zero address.
+type ERC20InvalidApprover struct {
+	Approver common.Address
+}
+
+func (e *ERC20InvalidApprover) Error() string {
+	return fmt.Sprintf("ERC20InvalidApprover: %s", e.Approver.Hex())
+}
+
+// ERC20InvalidSpender reverts an approval for an address that can't spend,
+// such as the zero address.
+type ERC20InvalidSpender struct {
+	Spender common.Address
+}
+
+func (e *ERC20InvalidSpender) Error() string {
+	return fmt.Sprintf("ERC20InvalidSpender: %s", e.Spender.Hex())
+}
+
+// typed builds the Go error for a decoded custom error.
+func typed(e abi.Error, values []interface{}) error {
+	switch e.Sig {
+	case "ERC20InsufficientBalance(address,uint256,uint256)":
+		return &ERC20InsufficientBalance{Sender: values[0].(common.Address), Balance: values[1].(*big.Int), Needed: values[2].(*big.Int)}
+	case "ERC20InvalidSender(address)":
+		return &ERC20InvalidSender{Sender: values[0].(common.Address)}
+	case "ERC20InvalidReceiver(address)":
+		return &ERC20InvalidReceiver{Receiver: values[0].(common.Address)}
+	case "ERC20InsufficientAllowance(address,uint256,uint256)":
+		return &ERC20InsufficientAllowance{Spender: values[0].(common.Address), Allowance: values[1].(*big.Int), Needed: values[2].(*big.Int)}
+	case "ERC20InvalidApprover(address)":
+		return &ERC20InvalidApprover{Approver: values[0].(common.Address)}
+	case "ERC20InvalidSpender(address)":
+		return &ERC20InvalidSpender{Spender: values[0].(common.Address)}
+	}
+
+	inputs := make([]string, len(e.Inputs))
+	for i, input := range e.Inputs {
+		inputs[i] = input.Name
+	}
+	return &CustomError{Name: e.Name, Inputs: inputs, Args: values}
+}
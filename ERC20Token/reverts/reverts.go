@@ -0,0 +1,208 @@
+// Package reverts turns revert data into Go errors: Error(string),
+// Panic(uint256) and the custom errors of every contract in the build
+// directory, with the OpenZeppelin ERC20 errors as their own types.
+package reverts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/build"
+)
+
+var (
+	errorSelector = [4]byte{0x08, 0xc3, 0x79, 0xa0} // Error(string)
+	panicSelector = [4]byte{0x4e, 0x48, 0x7b, 0x71} // Panic(uint256)
+
+	stringArgs, _  = abi.NewType("string", "", nil)
+	uint256Args, _ = abi.NewType("uint256", "", nil)
+)
+
+var (
+	loadOnce sync.Once
+	known    map[[4]byte]abi.Error
+	loadErr  error
+)
+
+// load collects the custom errors of all build ABIs by selector.
+func load() {
+	known = make(map[[4]byte]abi.Error)
+	loadErr = fs.WalkDir(build.ABIs, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		f, err := build.ABIs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		parsed, err := abi.JSON(f)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		for _, e := range parsed.Errors {
+			var selector [4]byte
+			copy(selector[:], e.ID[:4])
+			known[selector] = e
+		}
+		return nil
+	})
+}
+
+// Decode returns the error revert data stands for, or nil if it is empty or
+// matches no known error.
+func Decode(data []byte) error {
+	if len(data) < 4 {
+		return nil
+	}
+	var selector [4]byte
+	copy(selector[:], data[:4])
+
+	switch selector {
+	case errorSelector:
+		values, err := abi.Arguments{{Type: stringArgs}}.Unpack(data[4:])
+		if err != nil {
+			return nil
+		}
+		return &Reason{Message: values[0].(string)}
+	case panicSelector:
+		values, err := abi.Arguments{{Type: uint256Args}}.Unpack(data[4:])
+		if err != nil {
+			return nil
+		}
+		return &Panic{Code: values[0].(*big.Int)}
+	}
+
+	loadOnce.Do(load)
+	if loadErr != nil {
+		return nil
+	}
+	e, ok := known[selector]
+	if !ok {
+		return nil
+	}
+	values, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil
+	}
+	return typed(e, values)
+}
+
+// Data returns the revert data err carries, as nodes and the simulated
+// backend attach it to failed calls and gas estimates.
+func Data(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		decoded, err := hexutil.Decode(data)
+		return decoded, err == nil
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
+
+// Wrap decodes the revert data of err. If there is none, or it matches no
+// known error, err is returned as it is.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	data, ok := Data(err)
+	if !ok {
+		return err
+	}
+	reason := Decode(data)
+	if reason == nil {
+		return err
+	}
+	return &Reverted{Err: err, Reason: reason}
+}
+
+// Reverted is an error whose revert data was decoded into Reason. Both err
+// and Reason can be matched with errors.As.
+type Reverted struct {
+	Err    error
+	Reason error
+}
+
+func (e *Reverted) Error() string {
+	return "execution reverted: " + e.Reason.Error()
+}
+
+func (e *Reverted) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// Reason is a revert with Error(string), from require and revert("...").
+type Reason struct {
+	Message string
+}
+
+func (e *Reason) Error() string {
+	return e.Message
+}
+
+// Panic is a revert with Panic(uint256), from a failed assert, arithmetic
+// overflow, out-of-bounds access and the like.
+type Panic struct {
+	Code *big.Int
+}
+
+// panicCodes are the codes solc documents for Panic(uint256).
+var panicCodes = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to uninitialized function",
+}
+
+func (e *Panic) Error() string {
+	if e.Code.IsUint64() {
+		if description, ok := panicCodes[e.Code.Uint64()]; ok {
+			return fmt.Sprintf("panic 0x%x: %s", e.Code, description)
+		}
+	}
+	return fmt.Sprintf("panic 0x%x", e.Code)
+}
+
+// CustomError is a custom error of the build ABIs that has no type of its
+// own, such as the ERC721 and ERC1155 errors.
+type CustomError struct {
+	Name   string
+	Inputs []string // argument names
+	Args   []interface{}
+}
+
+func (e *CustomError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = fmt.Sprintf("%s: %v", e.Inputs[i], format(arg))
+	}
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+func format(arg interface{}) interface{} {
+	if address, ok := arg.(common.Address); ok {
+		return address.Hex()
+	}
+	return arg
+}
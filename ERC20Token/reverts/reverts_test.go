@@ -0,0 +1,141 @@
+package reverts_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/reverts"
+)
+
+var holder = common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+// revert encodes the revert data of the error with signature sig.
+func revert(t *testing.T, sig string, types []string, values ...interface{}) []byte {
+	t.Helper()
+	var args abi.Arguments
+	for _, name := range types {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	packed, err := args.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(crypto.Keccak256([]byte(sig))[:4], packed...)
+}
+
+func TestDecode(t *testing.T) {
+	balance := revert(t, "ERC20InsufficientBalance(address,uint256,uint256)", []string{"address", "uint256", "uint256"}, holder, big.NewInt(5), big.NewInt(7))
+	for _, test := range []struct {
+		name string
+		data []byte
+		want string // the error's message; empty for none
+	}{
+		{"empty", nil, ""},
+		{"shorter than a selector", []byte{0x08, 0xc3, 0x79}, ""},
+		{"reason", revert(t, "Error(string)", []string{"string"}, "not the owner"), "not the owner"},
+		{"reason cut short", revert(t, "Error(string)", []string{"string"}, "not the owner")[:40], ""},
+		{"known panic", revert(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)), "panic 0x11: arithmetic overflow or underflow"},
+		{"unknown panic", revert(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x99)), "panic 0x99"},
+		{"ERC20 error", balance, "ERC20InsufficientBalance: 0x1000000000000000000000000000000000000001 holds 5, needs 7"},
+		{"ERC20 error cut short", balance[:36], ""},
+		{"ERC20 error without arguments", balance[:4], ""},
+		{"ERC20 receiver", revert(t, "ERC20InvalidReceiver(address)", []string{"address"}, common.Address{}), "ERC20InvalidReceiver: 0x0000000000000000000000000000000000000000"},
+		{"other custom error", revert(t, "ERC721IncorrectOwner(address,uint256,address)", []string{"address", "uint256", "address"}, holder, big.NewInt(3), common.Address{}), "ERC721IncorrectOwner(sender: 0x1000000000000000000000000000000000000001, tokenId: 3, owner: 0x0000000000000000000000000000000000000000)"},
+		{"unknown selector", revert(t, "Unknown(uint256)", []string{"uint256"}, big.NewInt(1)), ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := reverts.Decode(test.data)
+			if test.want == "" {
+				if err != nil {
+					t.Fatalf("decoded %v, want nothing", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("decoded %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeERC20Types(t *testing.T) {
+	data := revert(t, "ERC20InsufficientAllowance(address,uint256,uint256)", []string{"address", "uint256", "uint256"}, holder, big.NewInt(1), big.NewInt(2))
+	var allowance *reverts.ERC20InsufficientAllowance
+	if err := reverts.Decode(data); !errors.As(err, &allowance) {
+		t.Fatalf("decoded %T, want *ERC20InsufficientAllowance", err)
+	}
+	if allowance.Spender != holder || allowance.Allowance.Int64() != 1 || allowance.Needed.Int64() != 2 {
+		t.Fatalf("decoded %+v", allowance)
+	}
+}
+
+// A transfer of more than the sender holds fails its gas estimate on the
+// simulated chain, with the token's custom error as revert data.
+func TestWrapSimulatedRevert(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connection.NewSimulatedSession(ctx, connection.Options{}, connection.NewKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, token, err := contractsgo.DeployTestERC20(auth, session.Client, "Test", "TST", 18, big.NewInt(100), session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	held, err := token.BalanceOf(nil, session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	needed := new(big.Int).Add(held, big.NewInt(1))
+
+	auth, err = session.NextTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = token.Transfer(auth, holder, needed)
+	session.TransactionFailed(auth, err)
+	if err == nil {
+		t.Fatal("a transfer of more than the balance was sent")
+	}
+
+	var balance *reverts.ERC20InsufficientBalance
+	if wrapped := reverts.Wrap(err); !errors.As(wrapped, &balance) {
+		t.Fatalf("wrapped %v, want *ERC20InsufficientBalance", wrapped)
+	}
+	if balance.Sender != session.From || balance.Balance.Cmp(held) != 0 || balance.Needed.Cmp(needed) != 0 {
+		t.Fatalf("decoded %+v", balance)
+	}
+	if !errors.Is(reverts.Wrap(err), err) {
+		t.Error("the wrapped error doesn't match the node's error")
+	}
+}
+
+func TestWrapWithoutRevertData(t *testing.T) {
+	err := errors.New("connection refused")
+	if wrapped := reverts.Wrap(err); wrapped != err {
+		t.Fatalf("wrapped %v, want the error unchanged", wrapped)
+	}
+	if reverts.Wrap(nil) != nil {
+		t.Fatal("wrapped nil into an error")
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+)
+
+const tokenUsage = "usage: ERC20Token token info|transfer|balances [flags]"
+
+// runToken works with any ERC20 token, -token or the deployed one: info
+// shows its metadata and supply, transfer sends some of it from the signer,
+// balances shows the ETH and token balances of accounts at a block.
+func runToken(args []string) error {
+	if len(args) == 0 {
+		return errors.New(tokenUsage)
+	}
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("token "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "info":
+		account := fs.String("account", "", "also show the token balance of this account")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		address, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		token, err := interact.OpenToken(ctx, session.Client, address)
+		if err != nil {
+			return err
+		}
+		supply, err := token.TotalSupply(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Token:", token.Address.Hex())
+		fmt.Println("Name:", token.Name)
+		fmt.Println("Symbol:", token.Symbol)
+		fmt.Println("Decimals:", token.Decimals)
+		fmt.Println("Total supply:", supply)
+
+		if *account != "" {
+			accountAddr, err := parseAddress("account", *account)
+			if err != nil {
+				return err
+			}
+			balance, err := token.BalanceOf(ctx, accountAddr)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Balance of %s: %s\n", accountAddr.Hex(), balance)
+		}
+		return nil
+
+	case "transfer":
+		to := fs.String("to", "", "recipient")
+		amount := fs.String("amount", "", "tokens to send, e.g. 1.5 or \"1.5 TST\"")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		toAddr, err := parseAddress("to", *to)
+		if err != nil {
+			return err
+		}
+		session, err := connection.NewSession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		token, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		value, err := parseAmount(ctx, session, token, *amount)
+		if err != nil {
+			return err
+		}
+		result, err := interact.TransferTokens(ctx, session, token, toAddr, value)
+		printBalances(result)
+		return err
+
+	case "balances":
+		block := fs.Int64("block", -1, "block to read the balances at (default latest)")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return errors.New("usage: ERC20Token token balances [-block n] address...")
+		}
+		accounts := make([]common.Address, fs.NArg())
+		for i, arg := range fs.Args() {
+			if accounts[i], err = parseAddress("address", arg); err != nil {
+				return err
+			}
+		}
+		session, err := connection.NewReadOnlySession(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		token, err := tokenAddress(cfg, session)
+		if err != nil {
+			return err
+		}
+		var blockNumber *big.Int
+		if *block >= 0 {
+			blockNumber = big.NewInt(*block)
+		}
+		snapshot, err := interact.TakeSnapshot(ctx, session.Client, token, blockNumber, accounts...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Balances at block %d:\n\n", snapshot.Block)
+		snapshot.Print()
+		return nil
+
+	default:
+		return errors.New(tokenUsage)
+	}
+}
+
+// printBalances shows the balances a transfer result carries, if any.
+func printBalances(result *interact.TransferResult) {
+	if result != nil && result.Balances != nil {
+		result.Balances.Print()
+	}
+}
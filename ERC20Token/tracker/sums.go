@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+)
+
+type TransferEvent struct {
+	From   common.Address
+	To     common.Address
+	Value  *big.Int
+	TxHash common.Hash
+}
+
+// Sums adds up the tokens every address received, since the last interval
+// reset and in total. It is safe for concurrent use.
+type Sums struct {
+	mu       sync.Mutex
+	unit     units.Unit
+	interval map[common.Address]*big.Int
+	total    map[common.Address]*big.Int
+}
+
+func NewSums() *Sums {
+	return &Sums{
+		interval: make(map[common.Address]*big.Int),
+		total:    make(map[common.Address]*big.Int),
+	}
+}
+
+func (s *Sums) Add(events []TransferEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+
+		if _, exists := s.interval[event.To]; !exists {
+			s.interval[event.To] = big.NewInt(0)
+		}
+		s.interval[event.To].Add(s.interval[event.To], event.Value)
+
+		if _, exists := s.total[event.To]; !exists {
+			s.total[event.To] = big.NewInt(0)
+		}
+		s.total[event.To].Add(s.total[event.To], event.Value)
+	}
+}
+
+// Revert takes events counted by Add out again.
+func (s *Sums) Revert(events []TransferEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		if sum, exists := s.interval[event.To]; exists {
+			sum.Sub(sum, event.Value)
+		}
+		if sum, exists := s.total[event.To]; exists {
+			sum.Sub(sum, event.Value)
+		}
+	}
+}
+
+// Total returns what address received in total.
+func (s *Sums) Total(address common.Address) *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sum, exists := s.total[address]; exists {
+		return new(big.Int).Set(sum)
+	}
+	return big.NewInt(0)
+}
+
+// SetUnit makes Print show the sums in tokens of unit instead of base units.
+func (s *Sums) SetUnit(unit units.Unit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unit = unit
+}
+
+func (s *Sums) Print() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Println("Interval Sums:")
+	for addr, sum := range s.interval {
+		fmt.Printf("%s: %s\n", addr.Hex(), s.unit.Format(sum))
+	}
+
+	fmt.Println("\nTotal Sums:")
+	for addr, sum := range s.total {
+		fmt.Printf("%s: %s\n", addr.Hex(), s.unit.Format(sum))
+	}
+	fmt.Println()
+}
+
+func (s *Sums) ResetInterval() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.interval = make(map[common.Address]*big.Int)
+}
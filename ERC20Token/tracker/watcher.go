@@ -0,0 +1,365 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+)
+
+// Tracking modes.
+const (
+	modeSubscription = "subscription"
+	modePolling      = "polling"
+)
+
+// maxLogRange caps the blocks asked for in one eth_getLogs call; many nodes
+// reject wider ranges.
+const maxLogRange = 2000
+
+// reorgDepth is how many blocks back a reorg is assumed to reach at most. The
+// watcher remembers what it applied from that many blocks behind the head, and
+// ignores logs from older blocks.
+const reorgDepth = 128
+
+// logID identifies a log within the chain.
+type logID struct {
+	block uint64
+	index uint
+	tx    common.Hash
+}
+
+// seenBlock is a block the watcher read logs from, as it was then.
+type seenBlock struct {
+	number uint64
+	hash   common.Hash
+}
+
+// Backend is what a Watcher reads from: logs, filtered or subscribed to, and
+// the chain's headers.
+type Backend interface {
+	bind.ContractFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Watcher follows the Transfer logs of a token into Sums. It prefers a
+// subscription to new heads and logs, and polls eth_getLogs whenever no
+// subscription can be made or the one it had drops. Logs seen twice while
+// switching between the two are counted once. Transfers a reorg removes are
+// taken out of the sums again, whether the subscription reports them removed
+// or polling finds their block replaced.
+type Watcher struct {
+	backend             Backend
+	filterer            *contractsgo.IERC20MetadataFilterer
+	sums                *Sums
+	resubscribeInterval time.Duration
+
+	mode          string
+	next          uint64                  // first block that may hold logs not yet applied
+	floor         uint64                  // logs of earlier blocks are ignored
+	applied       map[logID]TransferEvent // logs applied from block floor on
+	blocks        []seenBlock             // blocks read from block floor on, oldest first
+	resubscribeAt time.Time
+
+	logs    chan *contractsgo.IERC20MetadataTransfer
+	heads   chan *types.Header
+	logSub  event.Subscription
+	headSub event.Subscription
+}
+
+// NewWatcher returns a watcher that counts the token's transfers from block
+// start on into sums.
+func NewWatcher(backend Backend, token common.Address, start uint64, sums *Sums, resubscribeInterval time.Duration) (*Watcher, error) {
+	filterer, err := contractsgo.NewIERC20MetadataFilterer(token, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind token at %s: %v", token.Hex(), err)
+	}
+	return &Watcher{
+		backend:             backend,
+		filterer:            filterer,
+		sums:                sums,
+		resubscribeInterval: resubscribeInterval,
+		next:                start,
+		floor:               start,
+		applied:             make(map[logID]TransferEvent),
+	}, nil
+}
+
+// Mode returns the current tracking mode, "subscription" or "polling".
+func (w *Watcher) Mode() string {
+	return w.mode
+}
+
+// Run tracks transfers until ctx is done, printing and resetting the
+// interval sums on every tick.
+func (w *Watcher) Run(ctx context.Context, tick <-chan time.Time) error {
+	if err := w.subscribe(ctx); err != nil {
+		w.setMode(modePolling, fmt.Errorf("subscription unavailable: %v", err))
+	} else {
+		w.setMode(modeSubscription, nil)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if w.logSub != nil {
+				w.logSub.Unsubscribe()
+				w.headSub.Unsubscribe()
+			}
+			return ctx.Err()
+
+		case <-tick:
+			if w.mode == modePolling {
+				w.pollTick(ctx)
+			}
+			w.sums.Print()
+			w.sums.ResetInterval()
+
+		case transfer := <-w.logs:
+			w.apply(transfer)
+
+		case head := <-w.heads:
+			// logs of earlier blocks have been delivered by now; head itself
+			// is polled again if the subscription drops
+			if n := head.Number.Uint64(); n > w.next {
+				w.next = n
+				w.forget()
+			}
+
+		case err := <-subErr(w.logSub):
+			w.dropSubscription(fmt.Errorf("log subscription dropped: %v", err))
+
+		case err := <-subErr(w.headSub):
+			w.dropSubscription(fmt.Errorf("head subscription dropped: %v", err))
+		}
+	}
+}
+
+// pollTick tries to get back to a subscription now and then, and polls for
+// logs while there is none.
+func (w *Watcher) pollTick(ctx context.Context) {
+	if !time.Now().Before(w.resubscribeAt) {
+		if err := w.subscribe(ctx); err == nil {
+			w.setMode(modeSubscription, nil)
+			return
+		}
+		w.resubscribeAt = time.Now().Add(w.resubscribeInterval)
+	}
+
+	if err := w.Poll(ctx); err != nil {
+		fmt.Printf("Error polling Transfer logs: %v\n", err)
+	}
+}
+
+// subscribe subscribes to logs and heads, then polls once to catch up on
+// blocks mined while there was no subscription. Logs delivered by the
+// subscription meanwhile wait in the channel and are skipped as duplicates.
+func (w *Watcher) subscribe(ctx context.Context) error {
+	logs := make(chan *contractsgo.IERC20MetadataTransfer, 128)
+	logSub, err := w.filterer.WatchTransfer(&bind.WatchOpts{Context: ctx}, logs, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	heads := make(chan *types.Header, 16)
+	headSub, err := w.backend.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		logSub.Unsubscribe()
+		return err
+	}
+
+	if err := w.Poll(ctx); err != nil {
+		logSub.Unsubscribe()
+		headSub.Unsubscribe()
+		return fmt.Errorf("failed to catch up: %v", err)
+	}
+
+	w.logs, w.heads = logs, heads
+	w.logSub, w.headSub = logSub, headSub
+	return nil
+}
+
+func (w *Watcher) dropSubscription(reason error) {
+	w.logSub.Unsubscribe()
+	w.headSub.Unsubscribe()
+	w.logs, w.heads = nil, nil
+	w.logSub, w.headSub = nil, nil
+
+	// poll straight away on the next tick, resubscribe later
+	w.resubscribeAt = time.Now().Add(w.resubscribeInterval)
+	w.setMode(modePolling, reason)
+}
+
+func (w *Watcher) setMode(mode string, reason error) {
+	switch {
+	case w.mode == "" && reason != nil:
+		fmt.Printf("Tracking mode: %s (%v)\n", mode, reason)
+	case w.mode == "":
+		fmt.Printf("Tracking mode: %s\n", mode)
+	case reason != nil:
+		fmt.Printf("Tracking mode: %s -> %s (%v)\n", w.mode, mode, reason)
+	default:
+		fmt.Printf("Tracking mode: %s -> %s\n", w.mode, mode)
+	}
+	w.mode = mode
+}
+
+// Poll applies the Transfer logs from where the watcher stopped up to the
+// current head, after taking out those of blocks a reorg replaced since the
+// last poll. Run calls it as needed; call it directly only when Run isn't
+// running.
+func (w *Watcher) Poll(ctx context.Context) error {
+	if err := w.checkReorg(ctx); err != nil {
+		return err
+	}
+
+	header, err := w.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get head: %v", err)
+	}
+	head := header.Number.Uint64()
+
+	for w.next <= head {
+		end := head
+		if end-w.next >= maxLogRange {
+			end = w.next + maxLogRange - 1
+		}
+
+		it, err := w.filterer.FilterTransfer(&bind.FilterOpts{Start: w.next, End: &end, Context: ctx}, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get logs for blocks %d-%d: %v", w.next, end, err)
+		}
+		for it.Next() {
+			w.apply(it.Event)
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read logs for blocks %d-%d: %v", w.next, end, err)
+		}
+
+		w.next = end + 1
+	}
+	// a head mined on another branch than the logs were read from shows up
+	// as changed on the next poll
+	w.see(head, header.Hash())
+	w.forget()
+	return nil
+}
+
+// checkReorg compares the blocks read from, newest first, with the chain as
+// it is now, and rewinds to the newest one that is still there.
+func (w *Watcher) checkReorg(ctx context.Context) error {
+	for len(w.blocks) > 0 {
+		block := w.blocks[len(w.blocks)-1]
+		header, err := w.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(block.number))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to get block %d: %v", block.number, err)
+		}
+		if err == nil && header.Hash() == block.hash {
+			return nil
+		}
+		w.rewind(block.number)
+	}
+	return nil
+}
+
+// apply adds a transfer to the sums once. A transfer a reorg removes takes
+// everything applied from its block on out again, so it is all read anew
+// from the chain that replaced it; the other removed transfers then find
+// nothing left to take out.
+func (w *Watcher) apply(transfer *contractsgo.IERC20MetadataTransfer) {
+	id := logID{transfer.Raw.BlockNumber, transfer.Raw.Index, transfer.Raw.TxHash}
+	if id.block < w.floor {
+		return
+	}
+
+	if transfer.Raw.Removed {
+		if _, ok := w.applied[id]; ok {
+			w.rewind(id.block)
+		}
+		return
+	}
+
+	if _, ok := w.applied[id]; ok {
+		return
+	}
+	event := TransferEvent{
+		From:   transfer.From,
+		To:     transfer.To,
+		Value:  transfer.Value,
+		TxHash: transfer.Raw.TxHash,
+	}
+	w.sums.Add([]TransferEvent{event})
+	w.applied[id] = event
+	w.see(id.block, transfer.Raw.BlockHash)
+}
+
+// rewind takes the logs applied from block on out of the sums, and makes the
+// watcher read them again.
+func (w *Watcher) rewind(block uint64) {
+	var events []TransferEvent
+	for id, event := range w.applied {
+		if id.block >= block {
+			events = append(events, event)
+			delete(w.applied, id)
+		}
+	}
+	w.sums.Revert(events)
+
+	i := len(w.blocks)
+	for i > 0 && w.blocks[i-1].number >= block {
+		i--
+	}
+	w.blocks = w.blocks[:i]
+	if w.next > block {
+		w.next = block
+	}
+}
+
+// see records the hash block had when logs were read from it.
+func (w *Watcher) see(block uint64, hash common.Hash) {
+	if n := len(w.blocks); n > 0 && w.blocks[n-1].number >= block {
+		if w.blocks[n-1].number == block {
+			w.blocks[n-1].hash = hash
+		}
+		return
+	}
+	w.blocks = append(w.blocks, seenBlock{block, hash})
+}
+
+// forget drops what the watcher remembers of blocks deeper than reorgDepth.
+func (w *Watcher) forget() {
+	if w.next < w.floor+reorgDepth {
+		return
+	}
+	w.floor = w.next - reorgDepth
+	for id := range w.applied {
+		if id.block < w.floor {
+			delete(w.applied, id)
+		}
+	}
+	i := 0
+	for i < len(w.blocks) && w.blocks[i].number < w.floor {
+		i++
+	}
+	w.blocks = w.blocks[i:]
+}
+
+// subErr returns the error channel of sub, or nil (which blocks forever) when
+// there is no subscription.
+func subErr(sub event.Subscription) <-chan error {
+	if sub == nil {
+		return nil
+	}
+	return sub.Err()
+}
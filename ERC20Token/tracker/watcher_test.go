@@ -0,0 +1,191 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/contractsgo"
+)
+
+var recipients = []common.Address{
+	common.HexToAddress("0x1000000000000000000000000000000000000001"),
+	common.HexToAddress("0x2000000000000000000000000000000000000002"),
+	common.HexToAddress("0x3000000000000000000000000000000000000003"),
+	common.HexToAddress("0x4000000000000000000000000000000000000004"),
+}
+
+// tokenChain is a simulated chain with a TestERC20 whose supply the session
+// account holds.
+type tokenChain struct {
+	t       *testing.T
+	session *connection.Session
+	backend *connection.SimulatedBackend
+	token   *contractsgo.TestERC20
+	address common.Address
+}
+
+func newTokenChain(t *testing.T) *tokenChain {
+	t.Helper()
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := connection.NewSimulatedSession(ctx, connection.Options{}, connection.NewKeySigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(session.Close)
+
+	auth, err := session.NextTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, token, err := contractsgo.DeployTestERC20(auth, session.Client, "Test", "TST", 18, big.NewInt(1_000_000), session.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tokenChain{t, session, session.Client.(*connection.SimulatedBackend), token, address}
+}
+
+// transfer sends value tokens to to in a block of its own and returns the
+// block's hash. The nonce is read from the chain, which a reorg may have
+// taken back.
+func (c *tokenChain) transfer(to common.Address, value int64) common.Hash {
+	c.t.Helper()
+	ctx := context.Background()
+	auth, err := c.session.NextTransaction(ctx)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	nonce, err := c.backend.PendingNonceAt(ctx, c.session.From)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+	if _, err := c.token.Transfer(auth, to, big.NewInt(value)); err != nil {
+		c.t.Fatal(err)
+	}
+	return c.head()
+}
+
+func (c *tokenChain) head() common.Hash {
+	c.t.Helper()
+	header, err := c.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return header.Hash()
+}
+
+// reorg replaces the blocks after parent with blocks empty blocks, which
+// must be more than it replaces.
+func (c *tokenChain) reorg(parent common.Hash, blocks int) {
+	c.t.Helper()
+	if err := c.backend.Fork(context.Background(), parent); err != nil {
+		c.t.Fatal(err)
+	}
+	for i := 0; i < blocks; i++ {
+		c.backend.Commit()
+	}
+}
+
+func (c *tokenChain) watcher(sums *Sums) *Watcher {
+	c.t.Helper()
+	header, err := c.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	w, err := NewWatcher(c.backend, c.address, header.Number.Uint64()+1, sums, time.Hour)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return w
+}
+
+func checkTotals(t *testing.T, sums *Sums, want []int64) {
+	t.Helper()
+	for i, value := range want {
+		if got := sums.Total(recipients[i]); got.Cmp(big.NewInt(value)) != 0 {
+			t.Errorf("%s received %s, want %d", recipients[i].Hex(), got, value)
+		}
+	}
+}
+
+// waitTotals waits for a running watcher to bring sums to want.
+func waitTotals(t *testing.T, sums *Sums, want []int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		ok := true
+		for i, value := range want {
+			ok = ok && sums.Total(recipients[i]).Cmp(big.NewInt(value)) == 0
+		}
+		if ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	checkTotals(t, sums, want)
+}
+
+// A reorg reported through the subscription takes out every transfer it
+// removes, not only the first, and counts those of the new branch.
+func TestWatcherSubscriptionReorg(t *testing.T) {
+	chain := newTokenChain(t)
+	sums := NewSums()
+	w := chain.watcher(sums)
+	fork := chain.head()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx, nil) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	chain.transfer(recipients[0], 10)
+	chain.transfer(recipients[1], 20)
+	chain.transfer(recipients[2], 30)
+	waitTotals(t, sums, []int64{10, 20, 30, 0})
+
+	chain.reorg(fork, 4)
+	chain.transfer(recipients[3], 40)
+	waitTotals(t, sums, []int64{0, 0, 0, 40})
+}
+
+// Polling sees no removed logs; it finds the blocks it read replaced and
+// reads the new branch instead.
+func TestWatcherPollingReorg(t *testing.T) {
+	ctx := context.Background()
+	chain := newTokenChain(t)
+	sums := NewSums()
+	w := chain.watcher(sums)
+
+	chain.transfer(recipients[0], 10)
+	fork := chain.head()
+	chain.transfer(recipients[1], 20)
+	chain.transfer(recipients[2], 30)
+	if err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkTotals(t, sums, []int64{10, 20, 30, 0})
+
+	chain.reorg(fork, 2)
+	chain.transfer(recipients[3], 40)
+	if err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkTotals(t, sums, []int64{10, 0, 0, 40})
+
+	if err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkTotals(t, sums, []int64{10, 0, 0, 40})
+}
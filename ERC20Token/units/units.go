@@ -0,0 +1,108 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxUint256 is the largest amount an ERC20 contract can hold.
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Ether counts wei in ETH.
+var Ether = Unit{Symbol: "ETH", Decimals: 18}
+
+// Unit is what a token counts in: amounts are held in base units and shown
+// in tokens of Decimals base-unit digits. The zero Unit shows base units.
+type Unit struct {
+	Symbol   string
+	Decimals uint8
+}
+
+// Amount is a number of base units of a token.
+type Amount struct {
+	Value *big.Int
+	Unit  Unit
+}
+
+// Tokens returns n whole tokens.
+func (u Unit) Tokens(n int64) Amount {
+	return Amount{Value: new(big.Int).Mul(big.NewInt(n), u.one()), Unit: u}
+}
+
+// Amount wraps a number of base units.
+func (u Unit) Amount(value *big.Int) Amount {
+	return Amount{Value: value, Unit: u}
+}
+
+// Parse reads a token amount such as "250000", "1.5" or "1.5 TST". A symbol,
+// if given, must be the unit's. Negative amounts, amounts with more
+// fractional digits than the token has decimals and amounts beyond uint256
+// are refused.
+func (u Unit) Parse(s string) (Amount, error) {
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 0:
+		return Amount{}, errors.New("empty amount")
+	case len(fields) > 2:
+		return Amount{}, fmt.Errorf("amount %q: want a number and an optional symbol", s)
+	case len(fields) == 2 && !strings.EqualFold(fields[1], u.Symbol):
+		return Amount{}, fmt.Errorf("amount %q: token is %s, not %s", s, u.Symbol, fields[1])
+	}
+
+	whole, frac, _ := strings.Cut(fields[0], ".")
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return Amount{}, fmt.Errorf("amount %q is not a number", s)
+	}
+	if len(frac) > int(u.Decimals) {
+		return Amount{}, fmt.Errorf("amount %q has more than %d decimals", s, u.Decimals)
+	}
+
+	value, _ := new(big.Int).SetString(whole+frac+strings.Repeat("0", int(u.Decimals)-len(frac)), 10)
+	if value.Cmp(maxUint256) > 0 {
+		return Amount{}, fmt.Errorf("amount %q overflows uint256", s)
+	}
+	return Amount{Value: value, Unit: u}, nil
+}
+
+// Format shows a number of base units in tokens.
+func (u Unit) Format(value *big.Int) string {
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	abs := new(big.Int).Abs(value)
+	whole, frac := new(big.Int).QuoRem(abs, u.one(), new(big.Int))
+
+	s := whole.String()
+	if frac.Sign() != 0 {
+		digits := fmt.Sprintf("%0*s", int(u.Decimals), frac.String())
+		s += "." + strings.TrimRight(digits, "0")
+	}
+	if value.Sign() < 0 {
+		s = "-" + s
+	}
+	if u.Symbol != "" {
+		s += " " + u.Symbol
+	}
+	return s
+}
+
+// String shows the amount in tokens, e.g. "1.5 TST".
+func (a Amount) String() string {
+	return a.Unit.Format(a.Value)
+}
+
+func (u Unit) one() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u.Decimals)), nil)
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package units
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var tst = Unit{Symbol: "TST", Decimals: 18}
+
+func TestParse(t *testing.T) {
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	for _, test := range []struct {
+		unit Unit
+		in   string
+		want string // base units; empty if in is refused
+	}{
+		{tst, "1", "1000000000000000000"},
+		{tst, "1.5", "1500000000000000000"},
+		{tst, "1.5 TST", "1500000000000000000"},
+		{tst, " 2.5  tst ", "2500000000000000000"},
+		{tst, ".5", "500000000000000000"},
+		{tst, "3.", "3000000000000000000"},
+		{tst, "0.000000000000000001", "1"},
+		{tst, "0", "0"},
+		{Unit{Symbol: "USDC", Decimals: 6}, "1000000", "1000000000000"},
+		{Unit{}, max, max},
+
+		// too many decimals
+		{tst, "0.0000000000000000001", ""},
+		{Unit{Symbol: "USDC", Decimals: 6}, "1.0000001", ""},
+		{Unit{}, "1.5", ""},
+		// overflow
+		{Unit{}, max[:len(max)-1] + "6", ""},
+		{tst, "115792089237316195423570985008687907853269984665640564039457.584007913129639936", ""},
+		{tst, strings.Repeat("9", 80), ""},
+		// not amounts
+		{tst, "", ""},
+		{tst, ".", ""},
+		{tst, "-1", ""},
+		{tst, "1e18", ""},
+		{tst, "1,5", ""},
+		{tst, "1.5 ETH", ""},
+		{tst, "1.5 TST extra", ""},
+	} {
+		amount, err := test.unit.Parse(test.in)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", test.in, amount.Value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.in, err)
+			continue
+		}
+		if amount.Value.String() != test.want {
+			t.Errorf("Parse(%q) = %s, want %s", test.in, amount.Value, test.want)
+		}
+	}
+}
+
+// The largest amount that fits in uint256 with 18 decimals still parses.
+func TestParseMaxUint256(t *testing.T) {
+	in := "115792089237316195423570985008687907853269984665640564039457.584007913129639935"
+	amount, err := tst.Parse(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount.Value.Cmp(maxUint256) != 0 {
+		t.Errorf("Parse(%q) = %s, want 2^256-1", in, amount.Value)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, test := range []struct {
+		unit  Unit
+		value *big.Int
+		want  string
+	}{
+		{tst, big.NewInt(0), "0 TST"},
+		{tst, nil, "0 TST"},
+		{tst, big.NewInt(1), "0.000000000000000001 TST"},
+		{tst, tst.Tokens(1000).Value, "1000 TST"},
+		{tst, big.NewInt(1_500_000_000_000_000_000), "1.5 TST"},
+		{tst, big.NewInt(-1_500_000_000_000_000_000), "-1.5 TST"},
+		{Unit{Symbol: "USDC", Decimals: 6}, big.NewInt(1_000_001), "1.000001 USDC"},
+		{Unit{}, big.NewInt(42), "42"},
+		{Unit{Decimals: 2}, big.NewInt(1050), "10.5"},
+		{Unit{}, maxUint256, maxUint256.String()},
+	} {
+		if got := test.unit.Format(test.value); got != test.want {
+			t.Errorf("Format(%s) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+// What Format shows, Parse reads back.
+func TestFormatParse(t *testing.T) {
+	for _, value := range []string{"0", "1", "10", "123456789", "1000000000000000000", "1500000000000000001", maxUint256.String()} {
+		v, _ := new(big.Int).SetString(value, 10)
+		amount, err := tst.Parse(tst.Format(v))
+		if err != nil {
+			t.Errorf("Parse(Format(%s)): %v", value, err)
+			continue
+		}
+		if amount.Value.Cmp(v) != 0 {
+			t.Errorf("Parse(Format(%s)) = %s", value, amount.Value)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+)
+
+// labelsFile sits next to the key files and maps addresses to labels.
+const labelsFile = "labels.json"
+
+// Wallet is one account in the store.
+type Wallet struct {
+	Address common.Address
+	Label   string
+	Path    string // keystore file holding the encrypted key
+}
+
+// Store keeps private keys in a go-ethereum keystore directory, encrypted
+// with one passphrase, and a label for every account. Keys it generates can
+// be exported, or used to sign, later.
+type Store struct {
+	dir        string
+	passphrase string
+	keystore   *keystore.KeyStore
+
+	mu     sync.Mutex
+	labels map[common.Address]string
+}
+
+// Open opens (or creates) the store in dir. If it already holds keys, the
+// passphrase is checked against one of them. lightKDF trades the strength of
+// the encryption for speed when generating many keys, e.g. for simulations.
+func Open(dir, passphrase string, lightKDF bool) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %v", err)
+	}
+
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+
+	s := &Store{
+		dir:        dir,
+		passphrase: passphrase,
+		keystore:   keystore.NewKeyStore(dir, scryptN, scryptP),
+		labels:     make(map[common.Address]string),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, labelsFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read wallet labels: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s.labels); err != nil {
+			return nil, fmt.Errorf("failed to parse wallet labels: %v", err)
+		}
+	}
+
+	if accs := s.keystore.Accounts(); len(accs) > 0 {
+		if _, err := s.Key(accs[0].Address); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// OpenFromConfig opens the store configured in the wallets section, reading
+// the passphrase from its password file or the terminal.
+func OpenFromConfig(cfg config.Wallets) (*Store, error) {
+	passphrase, err := connection.ReadPassphrase(cfg.PasswordFile, "Wallet passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	return Open(cfg.Dir, passphrase, cfg.LightKDF)
+}
+
+// List returns all wallets, sorted by label and then address.
+func (s *Store) List() []Wallet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var wallets []Wallet
+	for _, acc := range s.keystore.Accounts() {
+		wallets = append(wallets, s.wallet(acc))
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		if wallets[i].Label != wallets[j].Label {
+			return wallets[i].Label < wallets[j].Label
+		}
+		return wallets[i].Address.Hex() < wallets[j].Address.Hex()
+	})
+	return wallets
+}
+
+// Find returns the wallet with the given label.
+func (s *Store) Find(label string) (Wallet, bool) {
+	for _, w := range s.List() {
+		if w.Label == label {
+			return w, true
+		}
+	}
+	return Wallet{}, false
+}
+
+// Get returns the wallet of address.
+func (s *Store) Get(address common.Address) (Wallet, error) {
+	acc, err := s.keystore.Find(accounts.Account{Address: address})
+	if err != nil {
+		return Wallet{}, fmt.Errorf("wallet %s: %v", address.Hex(), err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wallet(acc), nil
+}
+
+// New generates a key pair, saves it encrypted and labels it.
+func (s *Store) New(label string) (Wallet, error) {
+	if err := s.checkLabel(label); err != nil {
+		return Wallet{}, err
+	}
+
+	acc, err := s.keystore.NewAccount(s.passphrase)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to generate wallet: %v", err)
+	}
+	return s.setLabel(acc, label)
+}
+
+// Ensure returns n wallets labelled prefix-1 to prefix-n, generating the ones
+// that don't exist yet.
+func (s *Store) Ensure(prefix string, n int) ([]Wallet, error) {
+	wallets := make([]Wallet, n)
+	for i := range wallets {
+		label := fmt.Sprintf("%s-%d", prefix, i+1)
+
+		w, ok := s.Find(label)
+		if !ok {
+			var err error
+			if w, err = s.New(label); err != nil {
+				return nil, err
+			}
+		}
+		wallets[i] = w
+	}
+	return wallets, nil
+}
+
+// Import adds an existing private key to the store.
+func (s *Store) Import(key *ecdsa.PrivateKey, label string) (Wallet, error) {
+	if err := s.checkLabel(label); err != nil {
+		return Wallet{}, err
+	}
+
+	acc, err := s.keystore.ImportECDSA(key, s.passphrase)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to import key: %v", err)
+	}
+	return s.setLabel(acc, label)
+}
+
+// ImportKeystore adds a key from a keystore JSON file encrypted with
+// passphrase; the store re-encrypts it with its own passphrase.
+func (s *Store) ImportKeystore(keyJSON []byte, passphrase, label string) (Wallet, error) {
+	if err := s.checkLabel(label); err != nil {
+		return Wallet{}, err
+	}
+
+	acc, err := s.keystore.Import(keyJSON, passphrase, s.passphrase)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to import keystore: %v", err)
+	}
+	return s.setLabel(acc, label)
+}
+
+// Export returns the key of address as keystore JSON encrypted with
+// passphrase.
+func (s *Store) Export(address common.Address, passphrase string) ([]byte, error) {
+	keyJSON, err := s.keystore.Export(accounts.Account{Address: address}, s.passphrase, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export wallet %s: %v", address.Hex(), err)
+	}
+	return keyJSON, nil
+}
+
+// Key decrypts the private key of address.
+func (s *Store) Key(address common.Address) (*ecdsa.PrivateKey, error) {
+	w, err := s.Get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	keyJSON, err := os.ReadFile(w.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet %s: %v", address.Hex(), err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, s.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt wallet %s: %v", address.Hex(), err)
+	}
+	return key.PrivateKey, nil
+}
+
+// Signer returns a signer for the wallet of address, so that it can move the
+// tokens it received.
+func (s *Store) Signer(address common.Address) (*connection.KeySigner, error) {
+	key, err := s.Key(address)
+	if err != nil {
+		return nil, err
+	}
+	return connection.NewKeySigner(key), nil
+}
+
+// SetLabel relabels the wallet of address.
+func (s *Store) SetLabel(address common.Address, label string) error {
+	if err := s.checkLabel(label); err != nil {
+		return err
+	}
+
+	acc, err := s.keystore.Find(accounts.Account{Address: address})
+	if err != nil {
+		return fmt.Errorf("wallet %s: %v", address.Hex(), err)
+	}
+	_, err = s.setLabel(acc, label)
+	return err
+}
+
+// checkLabel refuses labels that are already taken; empty labels are fine.
+func (s *Store) checkLabel(label string) error {
+	if label == "" {
+		return nil
+	}
+	if w, ok := s.Find(label); ok {
+		return fmt.Errorf("label %q is already used by %s", label, w.Address.Hex())
+	}
+	return nil
+}
+
+func (s *Store) setLabel(acc accounts.Account, label string) (Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if label == "" {
+		delete(s.labels, acc.Address)
+	} else {
+		s.labels[acc.Address] = label
+	}
+
+	data, err := json.MarshalIndent(s.labels, "", "  ")
+	if err != nil {
+		return Wallet{}, err
+	}
+	// write a temporary file and rename it so a crash can't lose all labels
+	tmp := filepath.Join(s.dir, "."+labelsFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return Wallet{}, fmt.Errorf("failed to save wallet labels: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, labelsFile)); err != nil {
+		return Wallet{}, fmt.Errorf("failed to save wallet labels: %v", err)
+	}
+
+	return s.wallet(acc), nil
+}
+
+func (s *Store) wallet(acc accounts.Account) Wallet {
+	return Wallet{Address: acc.Address, Label: s.labels[acc.Address], Path: acc.URL.Path}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/wallet"
+)
+
+const walletUsage = "usage: ERC20Token wallet new|list|import|export [flags]"
+
+// runWallet manages the wallet store: wallet new, list, import and export.
+func runWallet(args []string) error {
+	if len(args) == 0 {
+		return errors.New(walletUsage)
+	}
+
+	fs := flag.NewFlagSet("wallet "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "new":
+		label := fs.String("label", "", "label of the wallet; with -count, labels are <label>-1 to <label>-<count>")
+		count := fs.Int("count", 1, "number of wallets to generate")
+		store, err := openWalletStore(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		for i := 1; i <= *count; i++ {
+			l := *label
+			if *count > 1 && l != "" {
+				l = fmt.Sprintf("%s-%d", l, i)
+			}
+			w, err := store.New(l)
+			if err != nil {
+				return err
+			}
+			printWallet(w)
+		}
+		return nil
+
+	case "list":
+		store, err := openWalletStore(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		for _, w := range store.List() {
+			printWallet(w)
+		}
+		return nil
+
+	case "import":
+		label := fs.String("label", "", "label of the imported wallet")
+		keyFile := fs.String("key-file", "", "file holding a hex private key")
+		keystoreFile := fs.String("keystore-file", "", "keystore JSON file to import")
+		keystorePasswordFile := fs.String("keystore-file-password-file", "", "file holding the passphrase of -keystore-file")
+		store, err := openWalletStore(fs, args[1:])
+		if err != nil {
+			return err
+		}
+
+		var w wallet.Wallet
+		switch {
+		case *keyFile != "" && *keystoreFile == "":
+			data, err := os.ReadFile(*keyFile)
+			if err != nil {
+				return fmt.Errorf("failed to read key file: %v", err)
+			}
+			var key *ecdsa.PrivateKey
+			if key, err = crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")); err != nil {
+				return fmt.Errorf("invalid private key: %v", err)
+			}
+			if w, err = store.Import(key, *label); err != nil {
+				return err
+			}
+		case *keystoreFile != "" && *keyFile == "":
+			keyJSON, err := os.ReadFile(*keystoreFile)
+			if err != nil {
+				return fmt.Errorf("failed to read keystore file: %v", err)
+			}
+			passphrase, err := connection.ReadPassphrase(*keystorePasswordFile, "Passphrase of the imported keystore: ")
+			if err != nil {
+				return err
+			}
+			if w, err = store.ImportKeystore(keyJSON, passphrase, *label); err != nil {
+				return err
+			}
+		default:
+			return errors.New("give either -key-file or -keystore-file")
+		}
+		printWallet(w)
+		return nil
+
+	case "export":
+		label := fs.String("label", "", "label of the wallet to export")
+		address := fs.String("address", "", "address of the wallet to export")
+		out := fs.String("out", "", "file to write to instead of stdout")
+		exportPasswordFile := fs.String("export-password-file", "", "file holding the passphrase to encrypt the exported keystore with")
+		raw := fs.Bool("raw", false, "export the unencrypted hex private key instead of a keystore file")
+		store, err := openWalletStore(fs, args[1:])
+		if err != nil {
+			return err
+		}
+
+		var addr common.Address
+		switch {
+		case *address != "" && common.IsHexAddress(*address):
+			addr = common.HexToAddress(*address)
+		case *address != "":
+			return fmt.Errorf("%q is not an address", *address)
+		case *label != "":
+			w, ok := store.Find(*label)
+			if !ok {
+				return fmt.Errorf("no wallet labelled %q", *label)
+			}
+			addr = w.Address
+		default:
+			return errors.New("give -address or -label")
+		}
+
+		var data []byte
+		if *raw {
+			key, err := store.Key(addr)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "WARNING: exporting an unencrypted private key")
+			data = []byte(fmt.Sprintf("%x\n", crypto.FromECDSA(key)))
+		} else {
+			passphrase, err := connection.ReadPassphrase(*exportPasswordFile, "Passphrase for the exported keystore: ")
+			if err != nil {
+				return err
+			}
+			if data, err = store.Export(addr, passphrase); err != nil {
+				return err
+			}
+			data = append(data, '\n')
+		}
+
+		if *out == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(*out, data, 0600)
+
+	default:
+		return errors.New(walletUsage)
+	}
+}
+
+func openWalletStore(fs *flag.FlagSet, args []string) (*wallet.Store, error) {
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.OpenFromConfig(cfg.Wallets)
+}
+
+func printWallet(w wallet.Wallet) {
+	label := w.Label
+	if label == "" {
+		label = "-"
+	}
+	fmt.Printf("%s  %s  %s\n", w.Address.Hex(), label, w.Path)
+}
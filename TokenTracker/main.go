@@ -1,8 +1,24 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
 
 func main() {
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	fmt.Println("Program starting...")
-	startTicker()
+	if cfg.Tracker.Source == config.TrackerHashFile {
+		startTicker(cfg)
+		return
+	}
+	watchTransfers(cfg)
 }
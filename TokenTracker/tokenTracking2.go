@@ -6,48 +6,68 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/tracker"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
 )
 
 var (
-	hashFilePath = "hash.txt"
-	mutex        sync.Mutex
-	intervalSums = make(map[common.Address]*big.Int)
-	totalSums    = make(map[common.Address]*big.Int)
-	mapMutex     sync.Mutex
+	mutex sync.Mutex
+	sums  = tracker.NewSums()
 )
 
-type TransferEvent struct {
-	From   common.Address
-	To     common.Address
-	Value  *big.Int
-	TxHash common.Hash
-}
-
-func startTicker() {
+func startTicker(cfg *config.Config) {
 	fmt.Println("Starting ticker...")
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(cfg.Tracker.Interval)
 	defer ticker.Stop()
 
+	var session *connection.Session
 	for {
 		fmt.Println("Tick at", time.Now())
 		select {
 		case <-ticker.C:
-			err := processTransactions()
+			if session == nil {
+				var err error
+				session, err = connection.NewReadOnlySession(context.Background(), cfg)
+				if err != nil {
+					fmt.Printf("Error connecting, retrying next tick: %v\n", err)
+					continue
+				}
+				setUnit(session, cfg)
+			}
+			err := processTransactions(session, cfg.Paths.HashFile)
 			if err != nil {
 				fmt.Printf("Error processing transactions: %v\n", err)
 			}
-			printMaps()
-			resetIntervalSums()
+			sums.Print()
+			sums.ResetInterval()
+		}
+	}
+}
+
+// setUnit shows the sums in tokens if the token is known; the hashes don't
+// need it, so without it they are shown in base units.
+func setUnit(session *connection.Session, cfg *config.Config) {
+	contract, err := interact.TokenAddressFromConfig(cfg, session.ChainID)
+	if err == nil {
+		var unit units.Unit
+		if unit, err = interact.TokenUnit(context.Background(), session.Client, contract.Hex()); err == nil {
+			sums.SetUnit(unit)
+			return
 		}
 	}
+	fmt.Printf("Error reading the token, showing base units: %v\n", err)
 }
 
-func processTransactions() error {
+func processTransactions(session *connection.Session, hashFilePath string) error {
 	mutex.Lock()
 	defer mutex.Unlock()
 
@@ -58,16 +78,23 @@ func processTransactions() error {
 	}
 	defer file.Close()
 
+	// hashes that fail (not mined yet, or the node kept failing after the
+	// retries) stay in the file for the next tick instead of being dropped
+	var pending []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		txHash := scanner.Text()
-		events, err := getEventsForHash(txHash)
+		if txHash == "" {
+			continue
+		}
+		events, err := getEventsForHash(session.Client, txHash) //read-only session, for pulling tx receipts only
 		if err != nil {
-			fmt.Printf("Error getting events for hash %s: %v\n", txHash, err)
+			fmt.Printf("Error getting events for hash %s, retrying next tick: %v\n", txHash, err)
+			pending = append(pending, txHash)
 			continue
 		}
 		// printEvents(events)
-		updateMaps(events)
+		sums.Add(events)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -75,27 +102,32 @@ func processTransactions() error {
 		return err
 	}
 
-	// Clear the file after processing
-	os.Truncate(hashFilePath, 0)
+	// Clear the file after processing, keeping the hashes still pending
+	var rest strings.Builder
+	for _, txHash := range pending {
+		rest.WriteString(txHash + "\n")
+	}
+	if err := os.WriteFile(hashFilePath, []byte(rest.String()), 0644); err != nil {
+		return fmt.Errorf("error rewriting hash file: %v", err)
+	}
 	return nil
 }
 
-func getEventsForHash(txHash string) ([]TransferEvent, error) {
+func getEventsForHash(backend bind.DeployBackend, txHash string) ([]tracker.TransferEvent, error) {
 	hash := common.HexToHash(txHash)
-	client := connection.GetClientForContractTx() //this client is for pulling tx receipt only
-	receipt, err := client.TransactionReceipt(context.Background(), hash)
+	receipt, err := backend.TransactionReceipt(context.Background(), hash)
 	if err != nil {
 		return nil, fmt.Errorf("error getting transaction receipt: %v", err)
 	}
 
-	var events []TransferEvent
+	var events []tracker.TransferEvent
 	for _, log := range receipt.Logs {
 		if len(log.Topics) == 3 && log.Topics[0] == common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef") {
 			from := common.HexToAddress(log.Topics[1].Hex())
 			to := common.HexToAddress(log.Topics[2].Hex())
 			value := new(big.Int).SetBytes(log.Data)
 
-			event := TransferEvent{
+			event := tracker.TransferEvent{
 				From:   from,
 				To:     to,
 				Value:  value,
@@ -108,7 +140,7 @@ func getEventsForHash(txHash string) ([]TransferEvent, error) {
 	return events, nil
 }
 
-func printEvents(events []TransferEvent) {
+func printEvents(events []tracker.TransferEvent) {
 	for _, event := range events {
 		fmt.Printf("Transaction Hash: %s\n", event.TxHash.Hex())
 		fmt.Printf("From: %s\n", event.From.Hex())
@@ -117,44 +149,3 @@ func printEvents(events []TransferEvent) {
 		fmt.Println("------------------------")
 	}
 }
-
-func updateMaps(events []TransferEvent) {
-	mapMutex.Lock()
-	defer mapMutex.Unlock()
-
-	for _, event := range events {
-
-		if _, exists := intervalSums[event.To]; !exists {
-			intervalSums[event.To] = big.NewInt(0)
-		}
-		intervalSums[event.To].Add(intervalSums[event.To], event.Value)
-
-		if _, exists := totalSums[event.To]; !exists {
-			totalSums[event.To] = big.NewInt(0)
-		}
-		totalSums[event.To].Add(totalSums[event.To], event.Value)
-	}
-}
-
-func printMaps() {
-	mapMutex.Lock()
-	defer mapMutex.Unlock()
-
-	fmt.Println("Interval Sums:")
-	for addr, sum := range intervalSums {
-		fmt.Printf("%s: %s\n", addr.Hex(), sum.String())
-	}
-
-	fmt.Println("\nTotal Sums:")
-	for addr, sum := range totalSums {
-		fmt.Printf("%s: %s\n", addr.Hex(), sum.String())
-	}
-	fmt.Println()
-}
-
-func resetIntervalSums() {
-	mapMutex.Lock()
-	defer mapMutex.Unlock()
-
-	intervalSums = make(map[common.Address]*big.Int)
-}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/tracker"
+)
+
+// watchTransfers connects, then tracks the token's Transfer logs and prints
+// the sums every tracker interval.
+func watchTransfers(cfg *config.Config) {
+	ctx := context.Background()
+	ticker := time.NewTicker(cfg.Tracker.Interval)
+	defer ticker.Stop()
+
+	var session *connection.Session
+	var contract common.Address
+	for session == nil {
+		var err error
+		session, err = connection.NewReadOnlySession(ctx, cfg)
+		if err == nil {
+			if contract, err = interact.TokenAddressFromConfig(cfg, session.ChainID); err != nil {
+				session.Close()
+				session = nil
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error connecting, retrying next tick: %v\n", err)
+			<-ticker.C
+		}
+	}
+	defer session.Close()
+
+	head, err := session.Client.BlockNumber(ctx)
+	for err != nil {
+		fmt.Printf("Error getting block number, retrying next tick: %v\n", err)
+		<-ticker.C
+		head, err = session.Client.BlockNumber(ctx)
+	}
+
+	if unit, err := interact.TokenUnit(ctx, session.Client, contract.Hex()); err != nil {
+		fmt.Printf("Error reading the token, showing base units: %v\n", err)
+	} else {
+		sums.SetUnit(unit)
+	}
+
+	watcher, err := tracker.NewWatcher(session.Client, contract, head+1, sums, cfg.Tracker.ResubscribeInterval)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Tracking Transfer logs of %s from block %d\n", contract.Hex(), head+1)
+	watcher.Run(ctx, ticker.C)
+}
@@ -1,9 +1,20 @@
 package main
 
-import "time"
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+)
 
 func main() {
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	done := make(chan bool)
-	RandomTransaction(5*time.Second, done)
+	RandomTransaction(cfg, done)
 	<-done
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"math/rand"
 	"os"
@@ -10,40 +13,103 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofrs/flock"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/config"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/connection"
 	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/interact"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/journal"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/units"
+	"github.com/ymytheresa/erc20-token-tracker/ERC20Token/wallet"
 )
 
 var randomAddresses []common.Address
 var fileLock *flock.Flock
 var mutex sync.Mutex
 
-func init() {
-	fileLock = flock.New("hash.txt")
-}
-
-func RandomTransaction(interval time.Duration, done chan bool) {
-	numberOfRandomAddresses := 10
-	randomAddresses := generateRandomAddresses(numberOfRandomAddresses)
+func RandomTransaction(cfg *config.Config, done chan bool) {
+	fileLock = flock.New(cfg.Paths.HashFile)
+	randomAddresses, err := recipientAddresses(cfg)
+	if err != nil {
+		log.Println("Error opening recipient wallets:", err)
+		go func() { done <- true }()
+		return
+	}
 	defer printAllAddresses()
 
 	go func() {
-		ticker := time.NewTicker(interval)
+		ticker := time.NewTicker(cfg.Generator.Interval)
 		defer ticker.Stop()
 
-		contractAddr, err := getContractAddress()
-		if err != nil {
-			log.Println("Error getting contract address:", err)
-			done <- true
-			return
-		}
-
+		var session *connection.Session
+		var j *journal.Journal
+		defer func() {
+			if j != nil {
+				j.Close()
+			}
+		}()
+		var contractAddr string
+		var unit units.Unit
 		for {
 			select {
 			case <-ticker.C:
-				txHash := transact(contractAddr, randomAddresses) //doesnt mean its transferring from contract address, its transferring from contract owner's address
-				if err := writeTransactionHash(txHash); err != nil {
-					log.Printf("Error writing transaction hash: %v", err)
+				if session == nil {
+					var err error
+					session, err = connection.NewSession(context.Background(), cfg)
+					if err != nil {
+						log.Printf("Error connecting, retrying next tick: %v", err)
+						continue
+					}
+					// an unconfirmed production chain won't become confirmed by retrying
+					if err := session.CheckWritable(); err != nil {
+						log.Println("Error:", err)
+						session.Close()
+						done <- true
+						return
+					}
+					// nor will a token missing from the registry appear
+					address, err := interact.TokenAddressFromConfig(cfg, session.ChainID)
+					if err != nil {
+						log.Println("Error getting contract address:", err)
+						session.Close()
+						done <- true
+						return
+					}
+					contractAddr = address.Hex()
+					// the journal only counts transfers on the chain connected to
+					if j == nil {
+						if j, err = interact.OpenJournal(context.Background(), session, cfg.Paths.JournalFile); err != nil {
+							log.Println("Error opening journal:", err)
+							session.Close()
+							done <- true
+							return
+						}
+					}
+					if unit, err = interact.TokenUnit(context.Background(), session.Client, contractAddr); err != nil {
+						log.Printf("Error reading the token, retrying next tick: %v", err)
+						session.Close()
+						session = nil
+						continue
+					}
 				}
+				// the session's nonce manager keeps parallel transfers from colliding
+				var wg sync.WaitGroup
+				for i := 0; i < cfg.Generator.TransfersPerTick; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						// transfers a previous run paid take no time; go on to the next
+						for {
+							txHash, skipped := transact(session, j, cfg.Generator.Campaign, nextTransfer(), contractAddr, unit, cfg.Generator.Treasury, randomAddresses) //doesnt mean its transferring from contract address, its transferring from contract owner's address
+							if skipped {
+								continue
+							}
+							if err := writeTransactionHash(cfg.Paths.HashFile, txHash); err != nil {
+								log.Printf("Error writing transaction hash: %v", err)
+							}
+							break
+						}
+					}()
+				}
+				wg.Wait()
 			case <-done:
 				return
 			}
@@ -51,17 +117,57 @@ func RandomTransaction(interval time.Duration, done chan bool) {
 	}()
 }
 
-func transact(contractAddr string, randomAddresses []common.Address) string {
-	recipient := randomAddresses[rand.Intn(len(randomAddresses))]
-	txHash, err := interact.TransferTokens(contractAddr, recipient, int64(rand.Intn(100))) //transferring tokens from contract owner's address to random address actually. but contract address is needed
+// transferCount numbers the generator's transfers within its campaign.
+var transferCount struct {
+	sync.Mutex
+	n int
+}
+
+func nextTransfer() int {
+	transferCount.Lock()
+	defer transferCount.Unlock()
+	transferCount.n++
+	return transferCount.n
+}
+
+// transact sends transfer n of the campaign: 1 to 100 tokens to a random
+// recipient, from the session account or, if treasury is set, from the
+// treasury's allowance. The recipient and amount are drawn from a source
+// seeded with the campaign and n, so a restarted generator makes the same
+// transfer n, which the journal then skips if it was paid; skipped reports
+// that.
+func transact(session *connection.Session, j *journal.Journal, campaign string, n int, contractAddr string, unit units.Unit, treasury string, randomAddresses []common.Address) (string, bool) {
+	seed := fnv.New64a()
+	fmt.Fprintf(seed, "%s:%d", campaign, n)
+	r := rand.New(rand.NewSource(int64(seed.Sum64())))
+	recipient := randomAddresses[r.Intn(len(randomAddresses))]
+	value := unit.Tokens(int64(r.Intn(100) + 1))
+	key := journal.Key{Campaign: campaign, Line: n, Recipient: recipient}
+
+	var result *interact.TransferResult
+	var err error
+	if treasury != "" {
+		result, err = interact.TransferFromOnce(context.Background(), session, j, key, contractAddr, common.HexToAddress(treasury), value)
+	} else {
+		result, err = interact.TransferOnce(context.Background(), session, j, key, contractAddr, value) //transferring tokens from contract owner's address to random address actually. but contract address is needed
+	}
+	if errors.Is(err, interact.ErrAlreadyTransferred) {
+		return "", true
+	}
+	var reverted *interact.RevertError
+	if errors.As(err, &reverted) {
+		// mined, but moved no tokens: keep it out of the hash file
+		log.Printf("Transfer to %s reverted: %v", recipient.Hex(), err)
+		return "", false
+	}
 	if err != nil {
 		log.Printf("Error in transaction: %v", err)
-		return "" // Return an empty string in case of error
+		return "", false // Return an empty string in case of error
 	}
-	return txHash
+	return result.TxHash.Hex(), false
 }
 
-func writeTransactionHash(txHash string) error {
+func writeTransactionHash(hashFilePath string, txHash string) error {
 	if txHash == "" {
 		return nil
 	}
@@ -78,7 +184,7 @@ func writeTransactionHash(txHash string) error {
 	}
 	defer fileLock.Unlock()
 
-	file, err := os.OpenFile("hash.txt", os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := os.OpenFile(hashFilePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
 		return fmt.Errorf("error opening file: %v", err)
 	}
@@ -91,22 +197,25 @@ func writeTransactionHash(txHash string) error {
 	return nil
 }
 
-func getContractAddress() (string, error) {
-	filePath := "./contract_address.txt"
-	address, err := os.ReadFile(filePath)
+// recipientAddresses returns the addresses of the wallets labelled
+// recipient-1 to recipient-n in the wallet store, generating missing ones, so
+// the keys of every airdrop recipient are kept.
+func recipientAddresses(cfg *config.Config) ([]common.Address, error) {
+	store, err := wallet.OpenFromConfig(cfg.Wallets)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	wallets, err := store.Ensure("recipient", cfg.Generator.Recipients)
+	if err != nil {
+		return nil, err
 	}
-	return string(address), nil
-}
 
-func generateRandomAddresses(n int) []common.Address {
-	addresses := make([]common.Address, n)
-	for i := 0; i < n; i++ {
-		addresses[i] = common.HexToAddress(fmt.Sprintf("0x%x", rand.Uint64()))
+	addresses := make([]common.Address, len(wallets))
+	for i, w := range wallets {
+		addresses[i] = w.Address
 	}
 	randomAddresses = addresses
-	return addresses
+	return addresses, nil
 }
 
 func printAllAddresses() {